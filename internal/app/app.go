@@ -1,36 +1,217 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"mime"
+	"net/http"
+	"os"
 	"strings"
 
 	"webextractor/internal/fetcher"
+	"webextractor/internal/har"
 	"webextractor/internal/htmlparser"
 	"webextractor/internal/io"
 	"webextractor/internal/neturl"
 	"webextractor/internal/parser"
 	"webextractor/internal/tui"
 	"webextractor/internal/types"
+	"webextractor/internal/warc"
 )
 
 // App représente l'application WebExtractor
 type App struct {
-	config  *types.ExtractionConfig
-	fetcher *fetcher.Fetcher
+	config   *types.ExtractionConfig
+	fetcher  fetcher.Fetcher
+	recorder *har.Recorder       // nil sans -har-record
+	archive  *warc.Recorder      // nil sans -warc-record
+	budget   *fetcher.Budget     // nil sans budget d'octets ni de temps
+	filter   *neturl.Filter      // règles -include/-exclude des liens suivis
+	checker  fetcher.LinkChecker // vérification HEAD/GET des liens, nil si le Fetcher n'en est pas un
+
+	// Document de départ obtenu par la requête configurée, réutilisé pour ne pas
+	// soumettre de nouveau un POST ou un formulaire
+	startDoc  *htmlparser.Node
+	startMeta *types.FetchMetadata
+}
+
+// Option personnalise une App à sa création.
+type Option func(*App)
+
+// WithFetcher remplace le client HTTP intégré par f (cache, double de test, client à
+// requêtes signées...). Les options de transport de la configuration (robots.txt, débit,
+// authentification, TLS, mode sûr, enregistrement HAR et WARC) sont alors à la charge de f.
+// Si f ne retourne pas de FetchMetadata, l'URL demandée en tient lieu.
+func WithFetcher(f fetcher.Fetcher) Option {
+	return func(app *App) {
+		app.fetcher = f
+	}
+}
+
+// defaultMetadata complète un Fetcher fourni par WithFetcher qui retourne un document
+// sans FetchMetadata, que l'application et les enveloppes (budget, meta refresh) lisent.
+type defaultMetadata struct {
+	fetcher.Fetcher
+}
+
+// FetchDocument transmet la requête et décrit une réponse sans métadonnées par son URL.
+func (d defaultMetadata) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	doc, meta, err := d.Fetcher.FetchDocument(ctx, req)
+	if err == nil && meta == nil {
+		meta = &types.FetchMetadata{URL: req.URL, FinalURL: req.URL}
+	}
+	return doc, meta, err
 }
 
 // New crée une nouvelle instance de l'application
-func New(config *types.ExtractionConfig) *App {
-	return &App{
-		config:  config,
-		fetcher: fetcher.New(config.Timeout),
+func New(config *types.ExtractionConfig, opts ...Option) *App {
+	app := &App{config: config}
+	for _, opt := range opts {
+		opt(app)
+	}
+	if app.fetcher == nil {
+		app.fetcher = app.newHTTPFetcher()
+	}
+	app.checker, _ = app.fetcher.(fetcher.LinkChecker)
+	if _, ok := app.fetcher.(*fetcher.HTTPFetcher); !ok {
+		app.fetcher = defaultMetadata{app.fetcher}
+	}
+	if config.ByteBudget > 0 || config.TimeBudget > 0 {
+		app.budget = fetcher.NewBudget(app.fetcher, config.ByteBudget, config.TimeBudget)
+		app.fetcher = app.budget
+		// Les vérifications de liens sont aussi décomptées du budget
+		if app.checker != nil {
+			app.checker = app.budget
+		}
 	}
+	if config.MetaRefresh > 0 {
+		app.fetcher = fetcher.NewMetaRefresh(app.fetcher, config.MetaRefresh)
+	}
+	return app
+}
+
+// newHTTPFetcher construit le client HTTP intégré à partir de la configuration.
+func (app *App) newHTTPFetcher() *fetcher.HTTPFetcher {
+	config := app.config
+	opts := fetcher.Options{
+		Timeout:      config.Timeout,
+		IgnoreRobots: config.IgnoreRobots,
+
+		RequestsPerSecond: config.RateLimit,
+		Burst:             config.Burst,
+		MaxConnsPerHost:   config.MaxConns,
+
+		Credentials: scopedCredentials(config),
+		TLS:         config.TLS,
+		Safe:        config.Safe,
+		MaxBodySize: config.MaxBodySize,
+		Doer:        app.doer,
+	}
+	// Le rejeu ne contacte aucun serveur : robots.txt (souvent absent de l'enregistrement,
+	// et dont le Crawl-delay ferait attendre) et les limites de débit ne s'appliquent pas.
+	if config.HARReplay != "" {
+		opts.IgnoreRobots = true
+		opts.RequestsPerSecond, opts.Burst, opts.MaxConnsPerHost = 0, 0, 0
+	}
+	return fetcher.NewWithOptions(opts)
+}
+
+// doer choisit comment les requêtes sont exécutées : en direct ou rejouées depuis un HAR,
+// puis éventuellement archivées en WARC et enregistrées en HAR.
+func (app *App) doer(client fetcher.Doer) fetcher.Doer {
+	d := client
+	if app.config.HARReplay != "" {
+		d = har.NewReplayer(app.config.HARReplay)
+	}
+	if app.config.WARCRecord != "" {
+		app.archive = warc.NewRecorder(d, app.config.WARCRecord, types.DefaultUserAgent.String())
+		d = app.archive
+	}
+	if app.config.HARRecord != "" {
+		app.recorder = har.NewRecorder(d, har.Creator{Name: "webextractor", Version: "0.1"})
+		d = app.recorder
+	}
+	return d
 }
 
 // Run exécute l'application
 func (app *App) Run() error {
+	return app.RunContext(context.Background())
+}
+
+// RunContext exécute l'application jusqu'à la fin ou jusqu'à l'annulation du contexte.
+// La durée totale est bornée par config.Deadline si elle est définie. En cas d'annulation,
+// le fichier de sortie n'est pas modifié. Les fichiers HAR et WARC éventuels sont écrits dans tous les cas.
+func (app *App) RunContext(ctx context.Context) error {
+	if app.config.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, app.config.Deadline)
+		defer cancel()
+	}
+
+	err := app.run(ctx)
+	if ctx.Err() != nil && err != nil {
+		err = fmt.Errorf("extraction interrupted: %w", err)
+	}
+
+	// Les échanges sont enregistrés même en cas d'échec, pour pouvoir l'analyser
+	if app.archive != nil {
+		if closeErr := app.archive.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to write WARC file: %w", closeErr)
+		}
+	}
+	if app.recorder != nil {
+		if saveErr := app.recorder.WriteFile(app.config.HARRecord); saveErr != nil && err == nil {
+			err = fmt.Errorf("failed to write HAR file: %w", saveErr)
+		}
+	}
+	return err
+}
+
+// run enchaîne les étapes de l'extraction
+func (app *App) run(ctx context.Context) error {
+	if app.config.TLS.InsecureSkipVerify {
+		printInsecureWarning()
+	}
+
+	filter, err := neturl.NewFilter(app.config.Include, app.config.Exclude, app.config.Normalize)
+	if err != nil {
+		return fmt.Errorf("invalid link rules: %w", err)
+	}
+	app.filter = filter
+
+	if app.config.WARCInput != "" {
+		return app.processWARCInput(ctx)
+	}
+
+	if err := checkCredentials(app.config); err != nil {
+		return err
+	}
+
+	if !app.config.Login.IsEmpty() {
+		fmt.Printf("🔐 Connexion via %s...\n", app.config.Login.URL)
+		if err := fetcher.Login(ctx, app.fetcher, app.config.Login); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Connexion réussie\n")
+	}
+
+	if app.config.Batch.Enabled() {
+		if app.config.Selectors.IsEmpty() {
+			return fmt.Errorf("batch mode requires -sel")
+		}
+		return app.runBatch(ctx)
+	}
+
+	if app.config.Crawl.Enabled {
+		if app.config.Selectors.IsEmpty() {
+			return fmt.Errorf("crawl mode requires -sel")
+		}
+		return app.runCrawl(ctx)
+	}
+
 	if app.config.Selectors.IsEmpty() {
-		if err := app.runInteractiveMode(); err != nil {
+		if err := app.runInteractiveMode(ctx); err != nil {
 			return fmt.Errorf("interactive mode failed: %w", err)
 		}
 	}
@@ -41,29 +222,34 @@ func (app *App) Run() error {
 	}
 
 	if app.config.IsStructuredMode() {
-		return app.processStructuredOutput()
+		return app.processStructuredOutput(ctx)
 	}
 
-	return app.processSelectorOutput()
+	return app.processSelectorOutput(ctx)
 }
 
 // runInteractiveMode gère le mode interactif de sélection
-func (app *App) runInteractiveMode() error {
+func (app *App) runInteractiveMode(ctx context.Context) error {
+	// L'entrée standard contient le document : elle ne peut pas servir aux commandes
+	if types.URLString(app.config.URL).IsStdin() {
+		return fmt.Errorf("reading the document from stdin requires -sel")
+	}
+
 	session := types.NewSessionState(app.config.URL)
 
 	for {
-		parsedURL, err := neturl.Parse(session.CurrentURL)
+		parsedURL, err := app.linkBase(session.CurrentURL)
 		if err != nil {
 			return fmt.Errorf("invalid URL '%s': %w", session.CurrentURL, err)
 		}
 
 		fmt.Printf("Fetching %s...\n", session.CurrentURL)
-		doc, err := app.fetcher.Fetch(session.CurrentURL)
+		doc, _, err := app.fetchPage(ctx, session.CurrentURL)
 		if err != nil {
 			return fmt.Errorf("fetch error for %s: %w", session.CurrentURL, err)
 		}
 
-		res, err := tui.PromptSelectors(doc, parsedURL)
+		res, err := tui.PromptSelectorsFiltered(ctx, doc, parsedURL, app.filter)
 		if err != nil {
 			return fmt.Errorf("TUI prompt failed: %w", err)
 		}
@@ -95,39 +281,238 @@ func (app *App) runInteractiveMode() error {
 	return nil
 }
 
+// fetchPage récupère une page. Le document de départ utilise la requête configurée
+// (méthode, corps ou soumission de formulaire), les autres pages un simple GET.
+// La requête configurée n'est envoyée qu'une fois : son document est ensuite réutilisé.
+func (app *App) fetchPage(ctx context.Context, url string) (*htmlparser.Node, *types.FetchMetadata, error) {
+	rc := app.config.Request
+	if url != app.config.URL || rc.IsEmpty() {
+		return fetcher.Get(ctx, app.fetcher, url)
+	}
+	if app.startDoc != nil {
+		return app.startDoc, app.startMeta, nil
+	}
+
+	var doc *htmlparser.Node
+	var meta *types.FetchMetadata
+	var err error
+	if rc.SubmitForm != "" {
+		doc, meta, err = fetcher.SubmitForm(ctx, app.fetcher, url, rc.SubmitForm, rc.Fields, rc.Files)
+	} else {
+		var req *types.FetchRequest
+		if req, err = fetcher.BuildRequest(url, rc); err != nil {
+			return nil, nil, err
+		}
+		doc, meta, err = app.fetcher.FetchDocument(ctx, req)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	app.startDoc, app.startMeta = doc, meta
+	return doc, meta, nil
+}
+
+// scopedCredentials limite l'envoi des identifiants à l'hôte de l'URL cible
+// pour ne pas les divulguer aux autres sites visités. Sans hôte explicite ni URL
+// cible distante (lot, modèle d'URL, document local), aucun identifiant n'est envoyé :
+// run refuse alors l'extraction (voir checkCredentials).
+func scopedCredentials(config *types.ExtractionConfig) types.Credentials {
+	creds := config.Credentials
+	if creds.IsEmpty() || creds.Host != "" {
+		return creds
+	}
+	if u, err := neturl.Parse(config.URL); err == nil && u.Host != "" && !config.Batch.Enabled() {
+		creds.Host = u.Host
+		return creds
+	}
+	return types.Credentials{}
+}
+
+// checkCredentials refuse des identifiants qui ne peuvent être limités à aucun hôte.
+func checkCredentials(config *types.ExtractionConfig) error {
+	if !config.Credentials.IsEmpty() && scopedCredentials(config).IsEmpty() {
+		return fmt.Errorf("credentials require -auth-host when the target has no single host (-urls, URL template, local document)")
+	}
+	return nil
+}
+
+// linkBase retourne l'URL utilisée pour résoudre les liens de la page courante :
+// -base-url pour le document de départ, sinon l'URL de la page elle-même.
+func (app *App) linkBase(current string) (*neturl.URL, error) {
+	if app.config.BaseURL != "" && current == app.config.URL {
+		return neturl.Parse(app.config.BaseURL)
+	}
+	return neturl.Parse(current)
+}
+
 // processStructuredOutput traite la sortie en mode structuré
-func (app *App) processStructuredOutput() error {
-	structuredResult := convertToStructuredResult(app.config.URL, app.config.StructuredData)
+func (app *App) processStructuredOutput(ctx context.Context) error {
+	structuredResult := convertToStructuredResult(app.displayURL(app.config.URL), app.config.StructuredData, app.config.Normalize)
+	for i, link := range structuredResult.Links {
+		structuredResult.Links[i] = app.displayURL(link)
+	}
 	fmt.Printf("✅ Extraction terminée avec format structuré\n")
-	printResultLocation(app.config.OutputPath)
+	printResultLocation(os.Stdout, app.config.OutputPath)
 
-	if err := io.WriteStructured(app.config.OutputPath.String(), structuredResult); err != nil {
+	if err := io.WriteStructuredContext(ctx, app.config.OutputPath.String(), structuredResult); err != nil {
 		return fmt.Errorf("failed to write structured output: %w", err)
 	}
 	return nil
 }
 
 // processSelectorOutput traite la sortie en mode sélecteurs
-func (app *App) processSelectorOutput() error {
+func (app *App) processSelectorOutput(ctx context.Context) error {
 	fmt.Printf("\n🔄 Extraction finale des données de %s...\n", app.config.URL)
-	doc, err := app.fetcher.Fetch(app.config.URL)
-	if err != nil {
-		return fmt.Errorf("fetch error: %w", err)
+	var document io.DocumentResult
+	if app.config.Paginates() {
+		var err error
+		if document, err = app.extractPages(ctx); err != nil {
+			return fmt.Errorf("fetch error: %w", err)
+		}
+	} else {
+		doc, meta, err := app.fetchPage(ctx, app.config.URL)
+		if err != nil {
+			return fmt.Errorf("fetch error: %w", err)
+		}
+		app.printPageMetadata(meta)
+		document = io.DocumentResult{
+			URL:       app.pageIdentity(app.config.URL, meta.Canonical),
+			Canonical: meta.Canonical,
+			Results:   extractUsingSelectors(doc, app.config.Selectors),
+		}
 	}
 
-	results := extractUsingSelectors(doc, app.config.Selectors)
 	extractionResult := types.NewExtractionResult(app.config.URL)
-	extractionResult.SetMetrics(countTotalMatches(results), len(app.config.Selectors))
+	extractionResult.SetMetrics(countTotalMatches(document.Results), len(app.config.Selectors))
 
 	fmt.Println(extractionResult.String())
-	printResultLocation(app.config.OutputPath)
+	printResultLocation(os.Stdout, app.config.OutputPath)
+
+	if err := io.WriteContext(ctx, app.config.OutputPath.String(), document); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// processWARCInput applique les sélecteurs à chaque réponse HTML d'une archive WARC,
+// sans accès réseau, et écrit un résultat par page capturée.
+func (app *App) processWARCInput(ctx context.Context) error {
+	if app.config.Selectors.IsEmpty() {
+		return fmt.Errorf("reading a WARC archive requires -sel")
+	}
+
+	file, err := os.Open(app.config.WARCInput)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := warc.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📦 Lecture de l'archive %s...\n", app.config.WARCInput)
+	var docs []io.DocumentResult
+	total := 0
+	err = reader.Each(func(record *warc.Record) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		doc, ok, err := archivedPage(ctx, record)
+		if err != nil || !ok {
+			return err
+		}
+
+		results := extractUsingSelectors(doc, app.config.Selectors)
+		total += countTotalMatches(results)
+		canonical := fetcher.CanonicalURL(doc, record.TargetURI())
+		docs = append(docs, io.DocumentResult{
+			URL:       app.pageIdentity(record.TargetURI(), canonical),
+			Canonical: canonical,
+			Results:   results,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-	if err := io.Write(app.config.OutputPath.String(), io.DocumentResult{URL: app.config.URL, Results: results}); err != nil {
+	fmt.Printf("✅ %d pages extraites, %d éléments\n", len(docs), total)
+	printResultLocation(os.Stdout, app.config.OutputPath)
+
+	if err := io.WriteDocumentsContext(ctx, app.config.OutputPath.String(), docs); err != nil {
 		return fmt.Errorf("failed to write output: %w", err)
 	}
 	return nil
 }
 
+// archivedPage analyse un enregistrement WARC s'il s'agit d'une réponse HTML réussie.
+func archivedPage(ctx context.Context, record *warc.Record) (*htmlparser.Node, bool, error) {
+	if record.Type() != warc.TypeResponse {
+		return nil, false, nil
+	}
+	resp, err := record.HTTPResponse()
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode != http.StatusOK || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return nil, false, nil
+	}
+
+	doc, err := fetcher.ParseDocument(ctx, resp.Header.Get("Content-Type"), resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", record.TargetURI(), err)
+	}
+	return doc, true, nil
+}
+
+// printPageMetadata affiche la connexion TLS, la page atteinte par meta refresh
+// et l'URL canonique quand elles apportent une information.
+func (app *App) printPageMetadata(meta *types.FetchMetadata) {
+	if meta.TLS != nil {
+		fmt.Printf("🔒 %s\n", meta.TLS)
+	}
+	if app.config.MetaRefresh > 0 && meta.FinalURL != meta.URL {
+		fmt.Printf("↪️  Page finale : %s\n", meta.FinalURL)
+	}
+	if meta.Canonical != "" && meta.Canonical != meta.FinalURL {
+		fmt.Printf("🔗 URL canonique : %s\n", meta.Canonical)
+	}
+}
+
+// pageIdentity retourne l'URL qui identifie une page dans les sorties : son URL
+// canonique avec -canonical, sinon l'URL donnée.
+func (app *App) pageIdentity(pageURL, canonical string) string {
+	if app.config.UseCanonical && canonical != "" {
+		return app.displayURL(canonical)
+	}
+	return app.displayURL(pageURL)
+}
+
+// displayURL retourne l'URL telle qu'écrite dans les sorties : hôte en Unicode avec
+// -unicode-hosts, sinon en ASCII (punycode).
+func (app *App) displayURL(rawurl string) string {
+	if app.config.UnicodeHosts {
+		return neturl.UnicodeURL(rawurl)
+	}
+	return neturl.ASCIIURL(rawurl)
+}
+
+// printPartial signale que l'extraction s'est arrêtée avant la fin
+func printPartial(w *os.File, err error) {
+	fmt.Fprintf(w, "⚠️  Résultats partiels : %v\n", err)
+}
+
+// printInsecureWarning signale que les certificats TLS ne sont pas vérifiés
+func printInsecureWarning() {
+	fmt.Fprintln(os.Stderr, "⚠️  ATTENTION : -insecure-skip-verify désactive la vérification des certificats TLS.")
+	fmt.Fprintln(os.Stderr, "⚠️  Les connexions HTTPS peuvent être interceptées : ne l'utilisez que pour des tests.")
+}
+
 // printNoSelectionMessage affiche un message quand aucune sélection n'est faite
 func printNoSelectionMessage() {
 	fmt.Println("\n🔄 Aucun élément sélectionné pour l'extraction.")
@@ -136,16 +521,26 @@ func printNoSelectionMessage() {
 }
 
 // printResultLocation affiche où les résultats sont sauvegardés
-func printResultLocation(outputPath types.OutputPath) {
+func printResultLocation(w *os.File, outputPath types.OutputPath) {
 	if outputPath.IsStdout() {
-		fmt.Printf("📤 Résultats affichés ci-dessous :\n\n")
+		fmt.Fprintf(w, "📤 Résultats affichés ci-dessous :\n\n")
 	} else {
-		fmt.Printf("📁 Résultats sauvegardés dans : %s\n", outputPath.String())
+		fmt.Fprintf(w, "📁 Résultats sauvegardés dans : %s\n", outputPath.String())
+	}
+}
+
+// progress retourne la sortie des messages de progression des lots et des crawls :
+// stderr quand les résultats sont écrits sur stdout, pour ne pas couper le flux NDJSON.
+func (app *App) progress() *os.File {
+	if app.config.OutputPath.IsStdout() {
+		return os.Stderr
 	}
+	return os.Stdout
 }
 
-// convertToStructuredResult convertit les données brutes en résultat structuré
-func convertToStructuredResult(url string, data map[string]any) io.StructuredResult {
+// convertToStructuredResult convertit les données brutes en résultat structuré.
+// Les liens sont dédupliqués sur leur forme normalisée selon rules.
+func convertToStructuredResult(url string, data map[string]any, rules neturl.Rules) io.StructuredResult {
 	result := io.StructuredResult{URL: url}
 
 	if title, ok := data["title"].(string); ok {
@@ -164,7 +559,7 @@ func convertToStructuredResult(url string, data map[string]any) io.StructuredRes
 		result.Paragraphs = paragraphs
 	}
 	if links, ok := data["links"].([]string); ok {
-		result.Links = links
+		result.Links = neturl.Dedupe(links, rules)
 	}
 	if images, ok := data["images"].([]string); ok {
 		result.Images = images
@@ -0,0 +1,691 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"webextractor/internal/fetcher"
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/io"
+	"webextractor/internal/neturl"
+	"webextractor/internal/types"
+)
+
+// memoryFetcher sert des pages en mémoire, sans réseau.
+type memoryFetcher map[string]string
+
+func (m memoryFetcher) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	doc, err := htmlparser.Parse(strings.NewReader(m[req.URL]))
+	return doc, &types.FetchMetadata{URL: req.URL, FinalURL: req.URL, BodySize: int64(len(m[req.URL]))}, err
+}
+
+func TestWithFetcher(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.json")
+	config := types.NewExtractionConfig("https://example.test/", "h1", out, time.Second)
+	pages := memoryFetcher{"https://example.test/": `<html><body><h1>In memory</h1></body></html>`}
+
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var result io.DocumentResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	if len(result.Results) != 1 || len(result.Results[0].Matches) != 1 || result.Results[0].Matches[0] != "In memory" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+// bareFetcher retourne les documents sans FetchMetadata.
+type bareFetcher map[string]string
+
+func (m bareFetcher) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	doc, err := htmlparser.Parse(strings.NewReader(m[req.URL]))
+	return doc, nil, err
+}
+
+func TestFetcherWithoutMetadata(t *testing.T) {
+	dir := t.TempDir()
+	pages := bareFetcher{
+		"https://example.test/":  `<html><body><h1>Index</h1><a href="/a">A</a></body></html>`,
+		"https://example.test/a": `<html><body><h1>A</h1></body></html>`,
+	}
+
+	config := types.NewExtractionConfig("https://example.test/", "h1", filepath.Join(dir, "out.json"), time.Second)
+	config.ByteBudget = 1 << 20
+	config.MetaRefresh = 1
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("single page: %v", err)
+	}
+
+	config.OutputPath = types.OutputPath(filepath.Join(dir, "crawl.ndjson"))
+	config.Crawl = types.CrawlConfig{Enabled: true, MaxDepth: 1, Workers: 1}
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("crawl: %v", err)
+	}
+	if data, _ := os.ReadFile(string(config.OutputPath)); !strings.Contains(string(data), `"https://example.test/a"`) {
+		t.Errorf("expected the linked page to be crawled, got %s", data)
+	}
+}
+
+func TestPaginationStopsOnBudget(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.json")
+	config := types.NewExtractionConfig("https://example.test/1", "h1", out, time.Second)
+	config.NextSelector = ".next"
+	config.ByteBudget = 100
+	pages := memoryFetcher{
+		"https://example.test/1": `<html><body><h1>One</h1><a class="next" href="/2">next</a></body></html>`,
+		"https://example.test/2": `<html><body><h1>Two</h1><a class="next" href="/3">next</a></body></html>`,
+		"https://example.test/3": `<html><body><h1>Three</h1></body></html>`,
+	}
+
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var result io.DocumentResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	if !result.Partial || !strings.Contains(result.StopReason, fetcher.ErrBudgetExhausted.Error()) {
+		t.Fatalf("expected partial result, got %+v", result)
+	}
+	if len(result.Pages) != 2 || strings.Join(result.Results[0].Matches, ",") != "One,Two" {
+		t.Fatalf("expected the pages fetched before the budget ran out, got %+v", result)
+	}
+}
+
+// recordingFetcher note les méthodes des requêtes reçues.
+type recordingFetcher struct {
+	memoryFetcher
+	methods []string
+}
+
+func (f *recordingFetcher) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	f.methods = append(f.methods, req.Method)
+	return f.memoryFetcher.FetchDocument(ctx, req)
+}
+
+func TestStartRequestSentOnce(t *testing.T) {
+	config := types.NewExtractionConfig("https://example.test/search", "h1", "", time.Second)
+	config.Request = types.RequestConfig{Method: http.MethodPost, Fields: neturl.Values{"q": {"shoes"}}}
+	pages := &recordingFetcher{memoryFetcher: memoryFetcher{"https://example.test/search": `<h1>Results</h1>`}}
+	app := New(config, WithFetcher(pages))
+
+	// Sélection interactive puis extraction finale
+	for i := 0; i < 2; i++ {
+		if _, _, err := app.fetchPage(context.Background(), config.URL); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if strings.Join(pages.methods, ",") != http.MethodPost {
+		t.Fatalf("expected the POST to be sent once, got %v", pages.methods)
+	}
+}
+
+func TestNextPageScheme(t *testing.T) {
+	app := New(types.NewExtractionConfig("https://example.test/", "h1", "", time.Second))
+	cases := []struct {
+		page, href string
+		want       bool
+	}{
+		{"https://example.test/1", "/2", true},
+		{"https://example.test/1", "file:///etc/passwd", false},
+		{"https://example.test/1", "javascript:next()", false},
+		{"file:///docs/1.html", "2.html", true},
+		{"file:///docs/1.html", "https://example.test/2", true},
+	}
+	for _, c := range cases {
+		doc, err := htmlparser.Parse(strings.NewReader(`<a rel="next" href="` + c.href + `">next</a>`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := app.nextPage(doc, c.page, c.page); ok != c.want {
+			t.Errorf("next link %q from %s: got %v, want %v", c.href, c.page, ok, c.want)
+		}
+	}
+}
+
+func TestCrawl(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.ndjson")
+	config := types.NewExtractionConfig("https://example.test/docs/", "h1", out, time.Second)
+	config.Crawl = types.CrawlConfig{Enabled: true, MaxDepth: 2, Scope: types.ScopePrefix, Workers: 2}
+	pages := memoryFetcher{
+		"https://example.test/docs/": `<html><body><h1>Index</h1>
+			<a href="a">A</a><a href="a?utm_source=nav">A</a><a href="b#top">B</a><a href="./b/">B</a><a href="/blog/">Blog</a><a href="https://other.test/docs/">Other</a></body></html>`,
+		"https://example.test/docs/a":    `<html><body><h1>A</h1><a href="/docs/">Index</a><a href="deep">Deep</a></body></html>`,
+		"https://example.test/docs/b":    `<html><body><h1>B</h1><a href="a">A</a></body></html>`,
+		"https://example.test/docs/deep": `<html><body><h1>Deep</h1><a href="deeper">Deeper</a></body></html>`,
+	}
+
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	depths := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var page io.DocumentResult
+		if err := json.Unmarshal([]byte(line), &page); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		depths[page.URL] = page.Depth
+	}
+	want := map[string]int{
+		"https://example.test/docs/":     0,
+		"https://example.test/docs/a":    1,
+		"https://example.test/docs/b":    1,
+		"https://example.test/docs/deep": 2,
+	}
+	if len(depths) != len(want) {
+		t.Fatalf("expected %d pages (no duplicates, out-of-scope links or pages beyond depth), got %v", len(want), depths)
+	}
+	for url, depth := range want {
+		if got, ok := depths[url]; !ok || got != depth {
+			t.Errorf("%s: expected depth %d, got %d (present: %v)", url, depth, got, ok)
+		}
+	}
+}
+
+func TestCrawlStartPageFails(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.ndjson")
+	config := types.NewExtractionConfig("https://example.test/", "h1", out, time.Second)
+	config.Crawl = types.CrawlConfig{Enabled: true, MaxDepth: 1, Scope: types.ScopeHost}
+	failing := failingFetcher{next: memoryFetcher{}, fail: "https://example.test/"}
+
+	if err := New(config, WithFetcher(failing)).RunContext(context.Background()); err == nil || !strings.Contains(err.Error(), "start page") {
+		t.Fatalf("expected the start page failure to be returned, got %v", err)
+	}
+}
+
+func TestCrawlScope(t *testing.T) {
+	start, _ := neturl.Parse("https://www.example.test:8443/docs/index.html")
+	tests := []struct {
+		scope types.CrawlScope
+		url   string
+		want  bool
+	}{
+		{types.ScopeHost, "https://www.example.test:8443/other", true},
+		{types.ScopeHost, "https://blog.example.test/", false},
+		{types.ScopeDomain, "https://blog.example.test/", true},
+		{types.ScopeDomain, "https://example.test/", true},
+		{types.ScopeDomain, "https://notexample.test/", false},
+		{types.ScopePrefix, "https://www.example.test:8443/docs/guide", true},
+		{types.ScopePrefix, "https://www.example.test:8443/blog/", false},
+		{types.ScopeHost, "file:///docs/", false},
+	}
+	for _, tt := range tests {
+		u, _ := neturl.Parse(tt.url)
+		if got := newCrawlScope(tt.scope, start).contains(u); got != tt.want {
+			t.Errorf("%s scope, %s: got %v, want %v", tt.scope, tt.url, got, tt.want)
+		}
+	}
+
+	// La portée domain suit la liste des suffixes publics
+	sites := []struct {
+		start, url string
+		want       bool
+	}{
+		{"https://a.example.co.uk/", "https://b.example.co.uk/", true},
+		{"https://a.example.co.uk/", "https://other.co.uk/", false},
+		{"https://x.github.io/", "https://y.github.io/", false},
+		{"https://x.github.io/", "https://x.github.io/repo/", true},
+	}
+	for _, tt := range sites {
+		start, _ := neturl.Parse(tt.start)
+		u, _ := neturl.Parse(tt.url)
+		if got := newCrawlScope(types.ScopeDomain, start).contains(u); got != tt.want {
+			t.Errorf("domain scope from %s, %s: got %v, want %v", tt.start, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestUnicodeHosts(t *testing.T) {
+	page := `<html><body><h1>A</h1></body></html>`
+	pages := memoryFetcher{
+		"https://xn--mller-kva.test/": `<html><body><h1>Index</h1>
+			<a href="https://müller.test/a">A</a><a href="https://MÜLLER.test/a">A</a><a href="/a">A</a></body></html>`,
+		"https://müller.test/a":        page,
+		"https://xn--mller-kva.test/a": page,
+	}
+
+	for _, unicode := range []bool{false, true} {
+		out := filepath.Join(t.TempDir(), "out.ndjson")
+		config := types.NewExtractionConfig("https://xn--mller-kva.test/", "h1", out, time.Second)
+		config.Crawl = types.CrawlConfig{Enabled: true, MaxDepth: 1, Workers: 1}
+		config.UnicodeHosts = unicode
+		if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, _ := os.ReadFile(out)
+		var urls []string
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var doc io.DocumentResult
+			json.Unmarshal([]byte(line), &doc)
+			urls = append(urls, doc.URL)
+		}
+		want := "https://xn--mller-kva.test/,https://xn--mller-kva.test/a"
+		if unicode {
+			want = "https://müller.test/,https://müller.test/a"
+		}
+		if strings.Join(urls, ",") != want {
+			t.Errorf("unicode=%v: expected one page per host form %s, got %v", unicode, want, urls)
+		}
+	}
+}
+
+// interruptingFetcher annule l'exécution en demandant une page donnée et note les pages demandées.
+type interruptingFetcher struct {
+	pages     memoryFetcher
+	interrupt string
+	cancel    context.CancelFunc
+	fetched   []string
+}
+
+func (f *interruptingFetcher) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	f.fetched = append(f.fetched, req.URL)
+	if req.URL == f.interrupt {
+		f.cancel()
+		return nil, nil, ctx.Err()
+	}
+	return f.pages.FetchDocument(ctx, req)
+}
+
+func TestBatchInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	list := filepath.Join(dir, "urls.txt")
+	os.WriteFile(list, []byte("https://example.test/a\nhttps://example.test/stop\nhttps://example.test/b\n"), 0o600)
+	out := filepath.Join(dir, "out.ndjson")
+	config := types.NewExtractionConfig("", "h1", out, time.Second)
+	config.Batch = types.BatchConfig{Source: list, Workers: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pages := &interruptingFetcher{pages: memoryFetcher{"https://example.test/a": `<h1>A</h1>`}, interrupt: "https://example.test/stop", cancel: cancel}
+	if err := New(config, WithFetcher(pages)).RunContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if data, _ := os.ReadFile(out); strings.Contains(string(data), "/stop") {
+		t.Errorf("interrupted URL written as a failure: %s", data)
+	}
+}
+
+func TestCrawlResume(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.ndjson")
+	config := types.NewExtractionConfig("https://example.test/", "h1", out, time.Second)
+	config.Crawl = types.CrawlConfig{Enabled: true, MaxDepth: 2, Workers: 1, StateDir: filepath.Join(dir, "state")}
+	pages := memoryFetcher{
+		"https://example.test/":     `<html><body><h1>Index</h1><a href="/a">A</a><a href="/b">B</a></body></html>`,
+		"https://example.test/a":    `<html><body><h1>A</h1><a href="/deep">Deep</a></body></html>`,
+		"https://example.test/b":    `<html><body><h1>B</h1><a href="/a">A</a></body></html>`,
+		"https://example.test/deep": `<html><body><h1>Deep</h1></body></html>`,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := &interruptingFetcher{pages: pages, interrupt: "https://example.test/b", cancel: cancel}
+	if err := New(config, WithFetcher(first)).RunContext(ctx); err == nil {
+		t.Fatal("expected the interrupted crawl to fail")
+	}
+
+	config.Crawl.Resume = true
+	second := &interruptingFetcher{pages: pages}
+	if err := New(config, WithFetcher(second)).RunContext(context.Background()); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if got := strings.Join(second.fetched, " "); got != "https://example.test/b https://example.test/deep" {
+		t.Errorf("expected only the remaining pages to be fetched, got %s", got)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var urls []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var page io.DocumentResult
+		if err := json.Unmarshal([]byte(line), &page); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		urls = append(urls, page.URL)
+	}
+	want := "https://example.test/ https://example.test/a https://example.test/b https://example.test/deep"
+	if got := strings.Join(urls, " "); got != want {
+		t.Errorf("expected each page once, got %s", got)
+	}
+
+	config.URL = "https://other.test/"
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err == nil {
+		t.Error("expected an error when resuming a different crawl")
+	}
+	config.URL = "https://example.test/"
+
+	changed := *config
+	changed.Crawl.MaxDepth = 3
+	if err := New(&changed, WithFetcher(pages)).RunContext(context.Background()); err == nil || !strings.Contains(err.Error(), "-depth 2") {
+		t.Errorf("expected an error when resuming with another -depth, got %v", err)
+	}
+	changed = *config
+	changed.Selectors = types.SelectorList{"h2"}
+	if err := New(&changed, WithFetcher(pages)).RunContext(context.Background()); err == nil || !strings.Contains(err.Error(), "-sel") {
+		t.Errorf("expected an error when resuming with other selectors, got %v", err)
+	}
+	changed = *config
+	changed.Exclude = []string{"/b"}
+	if err := New(&changed, WithFetcher(pages)).RunContext(context.Background()); err == nil || !strings.Contains(err.Error(), "-exclude") {
+		t.Errorf("expected an error when resuming with other link rules, got %v", err)
+	}
+}
+
+func TestLinkRules(t *testing.T) {
+	dir := t.TempDir()
+	pages := memoryFetcher{
+		"https://example.test/":                      `<html><body><h1>Index</h1><a href="/products/a">A</a><a href="/products/a?sort=price">A sorted</a><a href="/cart">Cart</a><a href="/products/a.pdf">PDF</a></body></html>`,
+		"https://example.test/products/a":            `<html><body><h1>A</h1><a class="next" href="/cart">Cart</a></body></html>`,
+		"https://example.test/products/a?sort=price": `<html><body><h1>A sorted</h1></body></html>`,
+		"https://example.test/cart":                  `<html><body><h1>Cart</h1></body></html>`,
+	}
+
+	out := filepath.Join(dir, "crawl.ndjson")
+	config := types.NewExtractionConfig("https://example.test/", "h1", out, time.Second)
+	config.Crawl = types.CrawlConfig{Enabled: true, MaxDepth: 1, Workers: 1}
+	config.Include = []string{"/products/*"}
+	config.Exclude = []string{"*sort=*", "*.pdf"}
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("crawl: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `"url":"https://example.test/products/a"`) {
+		t.Errorf("expected the start page and the included product only, got %s", data)
+	}
+
+	out = filepath.Join(dir, "pages.json")
+	config = types.NewExtractionConfig("https://example.test/products/a", "h1", out, time.Second)
+	config.NextSelector = ".next"
+	config.Exclude = []string{"/cart"}
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("pagination: %v", err)
+	}
+	var result io.DocumentResult
+	data, _ = os.ReadFile(out)
+	if err := json.Unmarshal(data, &result); err != nil || len(result.Pages) != 1 {
+		t.Errorf("expected pagination to stop before the excluded page, got %s", data)
+	}
+
+	config.Exclude = []string{"re:("}
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err == nil {
+		t.Error("expected an error for an invalid rule")
+	}
+}
+
+// checkingFetcher sert des pages en mémoire et répond aux vérifications de liens.
+type checkingFetcher struct {
+	memoryFetcher
+	statuses map[string]*fetcher.LinkStatus
+}
+
+func (f checkingFetcher) CheckLink(ctx context.Context, url string) (*fetcher.LinkStatus, error) {
+	if status, ok := f.statuses[url]; ok {
+		return status, nil
+	}
+	return &fetcher.LinkStatus{URL: url, Method: "HEAD", StatusCode: 200, FinalURL: url}, nil
+}
+
+func TestCrawlLinkGraphAndReport(t *testing.T) {
+	dir := t.TempDir()
+	pages := checkingFetcher{
+		memoryFetcher: memoryFetcher{
+			"https://example.test/":  `<html><body><h1>Index</h1><a href="/a" rel="nofollow">Page A</a><a href="https://other.test/gone">Gone</a><a href="https://blog.example.test/">Blog</a><a href="mailto:x@example.test">Mail</a></body></html>`,
+			"https://example.test/a": `<html><body><h1>A</h1><a href="https://other.test/gone#x">Gone again</a></body></html>`,
+		},
+		statuses: map[string]*fetcher.LinkStatus{
+			"https://other.test/gone": {Method: "GET", StatusCode: 404, FinalURL: "https://other.test/missing",
+				Redirects: []fetcher.Redirect{{URL: "https://other.test/gone", StatusCode: 301}}},
+		},
+	}
+	config := types.NewExtractionConfig("https://example.test/", "h1", filepath.Join(dir, "out.ndjson"), time.Second)
+	config.Crawl = types.CrawlConfig{Enabled: true, MaxDepth: 1, Workers: 1,
+		Graph: filepath.Join(dir, "links.csv"), Report: filepath.Join(dir, "report.json")}
+
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	graph, _ := os.ReadFile(config.Crawl.Graph)
+	want := "source,target,text,rel,external\n" +
+		"https://example.test/,https://example.test/a,Page A,nofollow,false\n" +
+		"https://example.test/,https://other.test/gone,Gone,,true\n" +
+		"https://example.test/,https://blog.example.test/,Blog,,false\n" +
+		"https://example.test/a,https://other.test/gone,Gone again,,true\n"
+	if string(graph) != want {
+		t.Errorf("unexpected graph:\n%s", graph)
+	}
+
+	var report io.LinkReport
+	data, _ := os.ReadFile(config.Crawl.Report)
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	if report.Checked != 3 || report.Broken != 1 {
+		t.Fatalf("expected 3 links checked and 1 broken, got %s", data)
+	}
+	broken := report.Links[0]
+	if broken.URL != "https://other.test/gone" || broken.Status != 404 || len(broken.Redirects) != 1 || !broken.External ||
+		strings.Join(broken.ReferencedBy, " ") != "https://example.test/ https://example.test/a" {
+		t.Errorf("unexpected broken link: %+v", broken)
+	}
+}
+
+func TestBudgetFlaggedInStreams(t *testing.T) {
+	dir := t.TempDir()
+	pages := memoryFetcher{
+		"https://example.test/":  `<html><body><h1>Index</h1><a href="/a">A</a><a href="/b">B</a></body></html>`,
+		"https://example.test/a": `<html><body><h1>A</h1></body></html>`,
+		"https://example.test/b": `<html><body><h1>B</h1></body></html>`,
+	}
+	lastRecord := func(path string) io.DocumentResult {
+		data, _ := os.ReadFile(path)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		var doc io.DocumentResult
+		if err := json.Unmarshal([]byte(lines[len(lines)-1]), &doc); err != nil {
+			t.Fatalf("invalid output %s: %v", data, err)
+		}
+		return doc
+	}
+
+	crawlOut := filepath.Join(dir, "crawl.ndjson")
+	config := types.NewExtractionConfig("https://example.test/", "h1", crawlOut, time.Second)
+	config.Crawl = types.CrawlConfig{Enabled: true, MaxDepth: 1, Scope: types.ScopeHost, Workers: 1}
+	config.ByteBudget = 50
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("crawl: %v", err)
+	}
+	if doc := lastRecord(crawlOut); !doc.Partial || !strings.Contains(doc.StopReason, fetcher.ErrBudgetExhausted.Error()) {
+		t.Errorf("expected the crawl output to end with a partial marker, got %+v", doc)
+	}
+
+	list := filepath.Join(dir, "urls.txt")
+	os.WriteFile(list, []byte("https://example.test/\nhttps://example.test/a\n"), 0o600)
+	batchOut := filepath.Join(dir, "batch.ndjson")
+	config = types.NewExtractionConfig("", "h1", batchOut, time.Second)
+	config.Batch = types.BatchConfig{Source: list, Workers: 1}
+	config.ByteBudget = 50
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	if doc := lastRecord(batchOut); doc.URL != "https://example.test/a" || !doc.Partial || doc.StopReason == "" {
+		t.Errorf("expected the URL stopped by the budget to be flagged, got %+v", doc)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	dir := t.TempDir()
+	list := filepath.Join(dir, "urls.txt")
+	os.WriteFile(list, []byte("https://example.test/a\n\n# commentaire\nhttps://example.test/missing\n  https://example.test/b  \n"), 0o644)
+	out := filepath.Join(dir, "out.ndjson")
+	config := types.NewExtractionConfig("", "h1", out, time.Second)
+	config.Batch = types.BatchConfig{Source: list, Workers: 2}
+	pages := checkingFetcher{memoryFetcher: memoryFetcher{
+		"https://example.test/a": `<html><body><h1>A</h1></body></html>`,
+		"https://example.test/b": `<html><body><h1>B</h1><h1>B2</h1></body></html>`,
+	}}
+	failing := failingFetcher{next: pages, fail: "https://example.test/missing"}
+
+	if err := New(config, WithFetcher(failing)).RunContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(out)
+	records := map[string]io.DocumentResult{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var doc io.DocumentResult
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		records[doc.URL] = doc
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected one record per URL, got %s", data)
+	}
+	if got := records["https://example.test/b"]; len(got.Results[0].Matches) != 2 || got.Error != "" {
+		t.Errorf("unexpected record: %+v", got)
+	}
+	if got := records["https://example.test/missing"]; got.Error == "" || got.Results == nil {
+		t.Errorf("expected an error record, got %+v", got)
+	}
+}
+
+func TestBatchTemplate(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.ndjson")
+	config := types.NewExtractionConfig("", "h1", out, time.Second)
+	template, err := neturl.ParseTemplate("https://example.test/search?q={term}&page={1..2}", map[string]string{"term": "red shoes,blue"})
+	if err != nil {
+		t.Fatalf("template: %v", err)
+	}
+	config.Batch = types.BatchConfig{Template: template, Workers: 2}
+	pages := memoryFetcher{}
+	for _, url := range []string{"https://example.test/search?q=red+shoes&page=1", "https://example.test/search?q=red+shoes&page=2",
+		"https://example.test/search?q=blue&page=1", "https://example.test/search?q=blue&page=2"} {
+		pages[url] = `<html><body><h1>` + url + `</h1></body></html>`
+	}
+
+	if err := New(config, WithFetcher(pages)).RunContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(out)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected one record per expanded URL, got %s", data)
+	}
+	for _, line := range lines {
+		var doc io.DocumentResult
+		json.Unmarshal([]byte(line), &doc)
+		if doc.Results[0].Matches[0] != doc.URL || !strings.Contains(doc.URL, "page="+doc.Params["2"]) ||
+			!strings.Contains(doc.URL, "q="+neturl.QueryEscape(doc.Params["term"])) {
+			t.Errorf("record not labelled by its parameters: %s", line)
+		}
+	}
+}
+
+func TestBatchCredentialsScope(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body><h1>" + r.Header.Get("Authorization") + "</h1></body></html>"))
+	})
+	trusted, other := httptest.NewServer(handler), httptest.NewServer(handler)
+	defer trusted.Close()
+	defer other.Close()
+
+	dir := t.TempDir()
+	list := filepath.Join(dir, "urls.txt")
+	os.WriteFile(list, []byte(trusted.URL+"/\n"+other.URL+"/\n"), 0o600)
+	out := filepath.Join(dir, "out.ndjson")
+	config := types.NewExtractionConfig("", "h1", out, 2*time.Second)
+	config.IgnoreRobots = true
+	config.Batch = types.BatchConfig{Source: list, Workers: 1}
+	config.Credentials = types.Credentials{Username: "alice", Password: "secret"}
+
+	if err := New(config).RunContext(context.Background()); err == nil || !strings.Contains(err.Error(), "-auth-host") {
+		t.Fatalf("expected credentials without a host to be refused, got %v", err)
+	}
+
+	config.Credentials.Host = strings.TrimPrefix(trusted.URL, "http://")
+	if err := New(config).RunContext(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var doc io.DocumentResult
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		sent := len(doc.Results) > 0 && strings.Join(doc.Results[0].Matches, "") != ""
+		if want := strings.HasPrefix(doc.URL, trusted.URL); sent != want {
+			t.Errorf("%s: credentials sent = %v, want %v", doc.URL, sent, want)
+		}
+	}
+}
+
+func TestHARReplayIgnoresRobots(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body><h1>Recorded</h1></body></html>"))
+	}))
+	dir := t.TempDir()
+	har := filepath.Join(dir, "run.har")
+
+	// Enregistré sans robots.txt, puis rejoué serveur arrêté sans -ignore-robots
+	config := types.NewExtractionConfig(srv.URL+"/", "h1", filepath.Join(dir, "live.json"), 2*time.Second)
+	config.IgnoreRobots = true
+	config.HARRecord = har
+	if err := New(config).RunContext(context.Background()); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	srv.Close()
+
+	out := filepath.Join(dir, "replay.json")
+	config = types.NewExtractionConfig(srv.URL+"/", "h1", out, 2*time.Second)
+	config.HARReplay = har
+	config.RateLimit, config.Burst = 0.001, 1
+	if err := New(config).RunContext(context.Background()); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if data, _ := os.ReadFile(out); !strings.Contains(string(data), "Recorded") {
+		t.Errorf("expected the replayed page in the output, got %s", data)
+	}
+}
+
+// failingFetcher fait échouer une URL donnée.
+type failingFetcher struct {
+	next fetcher.Fetcher
+	fail string
+}
+
+func (f failingFetcher) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	if req.URL == f.fail {
+		return nil, nil, errors.New("unexpected HTTP status: 404 Not Found")
+	}
+	return f.next.FetchDocument(ctx, req)
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"webextractor/internal/fetcher"
+	"webextractor/internal/io"
+	"webextractor/internal/neturl"
+	"webextractor/internal/types"
+)
+
+// batchJob est une URL d'un lot, avec les valeurs du modèle qui l'a produite.
+type batchJob struct {
+	url    string
+	params map[string]string
+}
+
+// batchResult est le résultat de l'extraction d'une URL d'un lot.
+type batchResult struct {
+	doc io.DocumentResult
+	err error
+}
+
+// runBatch applique les sélecteurs à chaque URL du lot (-urls, ou modèle d'URL) avec un
+// groupe borné de workers. Chaque URL produit une ligne NDJSON dès qu'elle est traitée,
+// avec les valeurs du modèle qui l'a produite ; un échec produit un enregistrement
+// d'erreur sans interrompre le lot. Un budget épuisé arrête le lot.
+func (app *App) runBatch(ctx context.Context) error {
+	batch := app.config.Batch
+	workers := batch.Workers
+	if workers <= 0 {
+		workers = types.DefaultBatchWorkers
+	}
+
+	var expanded []neturl.TemplateURL
+	input := os.Stdin
+	switch {
+	case batch.Template != nil:
+		var err error
+		if expanded, err = batch.Template.Expand(); err != nil {
+			return err
+		}
+	case batch.Source != "-":
+		file, err := os.Open(batch.Source) // #nosec G304 - fichier choisi par l'utilisateur
+		if err != nil {
+			return fmt.Errorf("failed to open URL list: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	out, err := io.NewStreamWriter(app.config.OutputPath.String())
+	if err != nil {
+		return fmt.Errorf("failed to open output: %w", err)
+	}
+	defer out.Close()
+
+	// stop interrompt la lecture et les extractions en cours quand le budget est épuisé
+	batchCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	if batch.Template != nil {
+		fmt.Fprintf(app.progress(), "📋 Extraction par lot de %d URLs du modèle %s (%d URLs à la fois)\n", len(expanded), batch.Template, workers)
+	} else {
+		fmt.Fprintf(app.progress(), "📋 Extraction par lot depuis %s (%d URLs à la fois)\n", batch.Source, workers)
+	}
+
+	jobs := make(chan batchJob)
+	var scanErr error
+	go func() {
+		defer close(jobs)
+		send := func(job batchJob) bool {
+			select {
+			case jobs <- job:
+				return true
+			case <-batchCtx.Done():
+				return false
+			}
+		}
+		if batch.Template != nil {
+			for _, u := range expanded {
+				if !send(batchJob{url: u.URL, params: u.Params}) {
+					return
+				}
+			}
+			return
+		}
+		scanner := bufio.NewScanner(input)
+		for scanner.Scan() {
+			url := strings.TrimSpace(scanner.Text())
+			if url == "" || strings.HasPrefix(url, "#") {
+				continue
+			}
+			if !send(batchJob{url: url}) {
+				return
+			}
+		}
+		scanErr = scanner.Err()
+	}()
+
+	results := make(chan batchResult)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				r := app.extractURL(batchCtx, job.url)
+				r.doc.Params = job.params
+				results <- r
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	succeeded, failed, total := 0, 0, 0
+	var stopped, writeErr error
+	for r := range results {
+		switch {
+		case errors.Is(r.err, fetcher.ErrBudgetExhausted) || (r.err != nil && stopped != nil && batchCtx.Err() != nil):
+			// URL interrompue par le budget : signalée comme partielle dans la sortie
+			if stopped == nil {
+				stopped = r.err
+				stop()
+			}
+			r.doc.Results = []io.Result{}
+			r.doc.Partial = true
+			r.doc.StopReason = stopped.Error()
+			fmt.Fprintf(app.progress(), "⏹  %s : %v\n", r.doc.URL, stopped)
+		case r.err != nil && ctx.Err() != nil && (errors.Is(r.err, context.Canceled) || errors.Is(r.err, context.DeadlineExceeded)):
+			continue // exécution interrompue (Ctrl+C, -deadline) : l'URL n'a pas été traitée
+		case r.err != nil:
+			failed++
+			r.doc.Results = []io.Result{}
+			r.doc.Error = r.err.Error()
+			fmt.Fprintf(app.progress(), "⚠️  %s : %v\n", r.doc.URL, r.err)
+		default:
+			succeeded++
+			total += countTotalMatches(r.doc.Results)
+			fmt.Fprintf(app.progress(), "📄 %s\n", r.doc.URL)
+		}
+		if err := out.Write(r.doc); err != nil && writeErr == nil {
+			writeErr = fmt.Errorf("failed to write output: %w", err)
+			stop()
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if scanErr != nil {
+		return fmt.Errorf("failed to read URL list: %w", scanErr)
+	}
+	if stopped != nil {
+		printPartial(app.progress(), stopped)
+	}
+	fmt.Fprintf(app.progress(), "✅ Lot terminé : %d URLs réussies, %d en échec, %d éléments extraits\n", succeeded, failed, total)
+	printResultLocation(app.progress(), app.config.OutputPath)
+	if succeeded == 0 && failed > 0 {
+		return fmt.Errorf("all %d URLs of the batch failed", failed)
+	}
+	return nil
+}
+
+// extractURL récupère une URL du lot et applique les sélecteurs.
+func (app *App) extractURL(ctx context.Context, url string) batchResult {
+	doc, meta, err := app.fetchPage(ctx, url)
+	if err != nil {
+		return batchResult{doc: io.DocumentResult{URL: url}, err: err}
+	}
+	return batchResult{doc: io.DocumentResult{
+		URL:       app.pageIdentity(meta.FinalURL, meta.Canonical),
+		Canonical: meta.Canonical,
+		Results:   extractUsingSelectors(doc, app.config.Selectors),
+	}}
+}
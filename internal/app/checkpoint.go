@@ -0,0 +1,119 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"webextractor/internal/linkgraph"
+	"webextractor/internal/neturl"
+)
+
+// checkpointFile est le nom du fichier d'état du crawl dans le répertoire -state.
+const checkpointFile = "crawl.json"
+
+// checkpointInterval est le délai minimal entre deux points de reprise écrits en cours de
+// niveau ; un point de reprise est aussi écrit à la fin de chaque niveau et à l'arrêt.
+var checkpointInterval = 5 * time.Second
+
+// crawlCheckpoint est l'état d'un crawl, suffisant pour le reprendre sans récupérer de
+// nouveau les pages déjà traitées ni dupliquer les enregistrements de la sortie.
+type crawlCheckpoint struct {
+	URL       string               `json:"url"`
+	Output    string               `json:"output"`
+	Normalize string               `json:"normalize"`
+	Settings  crawlSettings        `json:"settings"`
+	Offset    int64                `json:"offset"` // taille de la sortie au moment du point de reprise
+	Pages     int                  `json:"pages"`
+	Total     int                  `json:"total"`
+	Frontier  neturl.FrontierState `json:"frontier"`
+	Written   []string             `json:"written"`         // URLs finales déjà écrites, normalisées
+	Edges     []linkgraph.Edge     `json:"edges,omitempty"` // liens des pages écrites, avec -graph ou -check-links
+
+	// Niveau en cours : ses pages, et celles déjà traitées avec leurs liens
+	Level []neturl.FrontierEntry `json:"level,omitempty"`
+	Done  map[string]crawlDone   `json:"done,omitempty"`
+}
+
+// crawlSettings regroupe les options qui déterminent les enregistrements et la frontière
+// d'un crawl : les changer à la reprise mélangerait des résultats incompatibles.
+type crawlSettings struct {
+	Selectors []string `json:"selectors,omitempty"`
+	MaxDepth  int      `json:"max_depth"`
+	MaxPages  int      `json:"max_pages"`
+	Scope     string   `json:"scope"`
+	Include   []string `json:"include,omitempty"`
+	Exclude   []string `json:"exclude,omitempty"`
+}
+
+// crawlDone est une page du niveau en cours déjà traitée.
+type crawlDone struct {
+	Final string   `json:"final"`
+	Links []string `json:"links,omitempty"`
+}
+
+// loadCheckpoint lit le point de reprise du répertoire d'état.
+func loadCheckpoint(dir string) (*crawlCheckpoint, error) {
+	data, err := os.ReadFile(filepath.Join(dir, checkpointFile)) // #nosec G304 - répertoire choisi par l'utilisateur
+	if err != nil {
+		return nil, err
+	}
+	var state crawlCheckpoint
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	return &state, nil
+}
+
+// save écrit le point de reprise dans le répertoire d'état. Le fichier est écrit à côté
+// puis renommé : une interruption pendant l'écriture laisse le point de reprise précédent.
+func (c *crawlCheckpoint) save(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+checkpointFile+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, checkpointFile))
+}
+
+// matches vérifie que le point de reprise a été créé pour le même crawl, avec les mêmes
+// options que want.
+func (c *crawlCheckpoint) matches(want *crawlCheckpoint) error {
+	if c.URL != want.URL || c.Output != want.Output || c.Normalize != want.Normalize {
+		return fmt.Errorf("checkpoint was created for -url %s, -out %s and -normalize %s", c.URL, c.Output, c.Normalize)
+	}
+	got, set := c.Settings, want.Settings
+	switch {
+	case !slices.Equal(got.Selectors, set.Selectors):
+		return fmt.Errorf("checkpoint was created with -sel %q", strings.Join(got.Selectors, ","))
+	case got.MaxDepth != set.MaxDepth:
+		return fmt.Errorf("checkpoint was created with -depth %d", got.MaxDepth)
+	case got.MaxPages != set.MaxPages:
+		return fmt.Errorf("checkpoint was created with -max-pages %d", got.MaxPages)
+	case got.Scope != set.Scope:
+		return fmt.Errorf("checkpoint was created with -scope %s", got.Scope)
+	case !slices.Equal(got.Include, set.Include):
+		return fmt.Errorf("checkpoint was created with -include %q", strings.Join(got.Include, ","))
+	case !slices.Equal(got.Exclude, set.Exclude):
+		return fmt.Errorf("checkpoint was created with -exclude %q", strings.Join(got.Exclude, ","))
+	}
+	return nil
+}
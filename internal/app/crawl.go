@@ -0,0 +1,396 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"webextractor/internal/fetcher"
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/io"
+	"webextractor/internal/linkgraph"
+	"webextractor/internal/neturl"
+	"webextractor/internal/parser"
+	"webextractor/internal/types"
+)
+
+// crawledPage est le résultat du traitement d'une page par un worker.
+type crawledPage struct {
+	page  neturl.FrontierEntry
+	final string // URL après redirections
+	doc   io.DocumentResult
+	links []string         // liens absolus dans la portée, dans l'ordre du document
+	edges []linkgraph.Edge // tous les liens HTTP(S) de la page, pour le graphe
+	err   error
+}
+
+// runCrawl parcourt le site en largeur à partir de -url : chaque niveau de profondeur est
+// traité par un groupe borné de workers, les résultats sont écrits page par page (NDJSON)
+// dès qu'ils sont prêts, et les liens du niveau forment le suivant, dans l'ordre de découverte.
+// Les URLs sont dédupliquées sur leur forme normalisée (config.Normalize). Avec un répertoire
+// d'état, l'état du crawl y est enregistré régulièrement pour pouvoir le reprendre (Resume).
+func (app *App) runCrawl(ctx context.Context) error {
+	crawl := app.config.Crawl
+	maxPages := crawl.MaxPages
+	if maxPages <= 0 {
+		maxPages = types.DefaultCrawlPages
+	}
+	workers := crawl.Workers
+	if workers <= 0 {
+		workers = types.DefaultCrawlWorkers
+	}
+
+	start, err := app.linkBase(app.config.URL)
+	if err != nil {
+		return fmt.Errorf("invalid URL '%s': %w", app.config.URL, err)
+	}
+	scope := newCrawlScope(crawl.Scope, start)
+
+	output := app.config.OutputPath.String()
+	state := &crawlCheckpoint{
+		URL:       app.config.URL,
+		Output:    output,
+		Normalize: app.config.Normalize.String(),
+		Settings: crawlSettings{
+			Selectors: app.config.Selectors,
+			MaxDepth:  crawl.MaxDepth,
+			MaxPages:  maxPages,
+			Scope:     string(scope.kind),
+			Include:   app.config.Include,
+			Exclude:   app.config.Exclude,
+		},
+	}
+	var out *io.StreamWriter
+	if crawl.Resume {
+		want := state
+		if state, err = loadCheckpoint(crawl.StateDir); err != nil {
+			return fmt.Errorf("failed to load checkpoint: %w", err)
+		}
+		if err := state.matches(want); err != nil {
+			return err
+		}
+		out, err = io.ResumeStreamWriter(output, state.Offset)
+	} else {
+		out, err = io.NewStreamWriter(output)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open output: %w", err)
+	}
+	defer out.Close()
+
+	fmt.Fprintf(app.progress(), "🕷  Crawl de %s (profondeur %d, %d pages au plus, portée %s)\n", app.config.URL, crawl.MaxDepth, maxPages, scope.kind)
+
+	frontier := neturl.RestoreFrontier(app.config.Normalize, state.Frontier)
+	written := neturl.RestoreFrontier(app.config.Normalize, neturl.FrontierState{Seen: state.Written}) // URLs finales déjà écrites (redirections vers une même page)
+	level, done := state.Level, state.Done
+	pages, total := state.Pages, state.Total
+	edges := state.Edges // liens des pages écrites, conservés pour -graph et -check-links
+	keepEdges := crawl.Graph != "" || crawl.Report != ""
+	if crawl.Resume {
+		fmt.Fprintf(app.progress(), "↩️  Reprise : %d pages déjà écrites, %d en attente\n", pages, len(level)-len(done)+frontier.Len())
+	} else {
+		frontier.Push(app.config.URL, 0)
+	}
+
+	// checkpoint enregistre l'état courant : le niveau en cours et ses pages traitées
+	// sont conservés pour ne reprendre que les pages restantes
+	checkpoint := func() error {
+		if crawl.StateDir == "" {
+			return nil
+		}
+		state.Offset, state.Pages, state.Total = out.Offset(), pages, total
+		state.Frontier, state.Written = frontier.State(), written.State().Seen
+		state.Level, state.Done = level, done
+		state.Edges = edges
+		if err := state.save(crawl.StateDir); err != nil {
+			return fmt.Errorf("failed to save checkpoint: %w", err)
+		}
+		return nil
+	}
+
+	var stopped, startErr error
+	for stopped == nil {
+		if len(level) == 0 {
+			// La file ne contient qu'un niveau à la fois : les liens sont ajoutés après le niveau
+			for entry, ok := frontier.Pop(); ok; entry, ok = frontier.Pop() {
+				if len(level) < maxPages-pages {
+					level = append(level, entry)
+				}
+			}
+			if len(level) == 0 {
+				break
+			}
+			done = map[string]crawlDone{}
+		}
+
+		var pending []neturl.FrontierEntry
+		for _, entry := range level {
+			if _, ok := done[entry.URL]; !ok {
+				pending = append(pending, entry)
+			}
+		}
+
+		saved := time.Now()
+		results := app.crawlLevel(ctx, pending, workers, scope, func(r crawledPage) {
+			if r.err != nil || stopped != nil {
+				return
+			}
+			done[r.page.URL] = crawlDone{Final: r.final, Links: r.links}
+			if written.MarkSeen(r.final) {
+				pages++
+				total += countTotalMatches(r.doc.Results)
+				fmt.Fprintf(app.progress(), "📄 [%d] %s\n", r.page.Depth, r.doc.URL)
+				if err := out.Write(r.doc); err != nil {
+					stopped = fmt.Errorf("failed to write output: %w", err)
+					return
+				}
+				if keepEdges {
+					edges = append(edges, r.edges...)
+				}
+			}
+			if time.Since(saved) >= checkpointInterval {
+				stopped = checkpoint()
+				saved = time.Now()
+			}
+		})
+		if err := ctx.Err(); err != nil {
+			if saveErr := checkpoint(); saveErr != nil {
+				fmt.Fprintf(app.progress(), "⚠️  %v\n", saveErr)
+			}
+			return err
+		}
+		if stopped != nil {
+			break
+		}
+
+		for _, r := range results {
+			switch {
+			case errors.Is(r.err, fetcher.ErrBudgetExhausted):
+				if stopped == nil {
+					stopped = r.err
+				}
+			case r.err != nil:
+				fmt.Fprintf(app.progress(), "⚠️  %s : %v\n", r.page.URL, r.err)
+				if r.page.Depth == 0 {
+					startErr = r.err
+				}
+			}
+		}
+		if stopped != nil {
+			// Les pages arrêtées par le budget restent à traiter lors d'une reprise ; la
+			// sortie se termine par un enregistrement qui le signale, retiré à la reprise
+			if err := checkpoint(); err != nil {
+				return err
+			}
+			marker := io.DocumentResult{URL: app.config.URL, Results: []io.Result{}, Partial: true, StopReason: stopped.Error()}
+			if err := out.Write(marker); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			break
+		}
+
+		// Le niveau suivant suit l'ordre des pages du niveau, reprises ou non
+		for _, entry := range level {
+			page, ok := done[entry.URL]
+			if !ok {
+				continue
+			}
+			frontier.MarkSeen(page.Final)
+			if entry.Depth >= crawl.MaxDepth {
+				continue
+			}
+			for _, link := range page.Links {
+				if pages+frontier.Len() >= maxPages {
+					break
+				}
+				frontier.Push(link, entry.Depth+1)
+			}
+		}
+		level, done = nil, nil
+		stopped = checkpoint()
+	}
+
+	if stopped != nil && !errors.Is(stopped, fetcher.ErrBudgetExhausted) {
+		return stopped
+	}
+	// Sans page de départ, le crawl a échoué même si la sortie a été écrite
+	if startErr == nil && stopped != nil && pages == 0 {
+		startErr = stopped
+	}
+	if startErr != nil {
+		return fmt.Errorf("start page %s: %w", app.config.URL, startErr)
+	}
+	if stopped != nil {
+		printPartial(app.progress(), stopped)
+	}
+	fmt.Fprintf(app.progress(), "✅ Crawl terminé : %d pages, %d éléments extraits\n", pages, total)
+	printResultLocation(app.progress(), app.config.OutputPath)
+	return app.writeLinkOutputs(ctx, edges, workers)
+}
+
+// writeLinkOutputs exporte le graphe des liens et vérifie les liens découverts, selon
+// -graph et -check-links.
+func (app *App) writeLinkOutputs(ctx context.Context, edges []linkgraph.Edge, workers int) error {
+	crawl := app.config.Crawl
+	if crawl.Graph != "" {
+		if err := linkgraph.WriteFile(crawl.Graph, edges); err != nil {
+			return fmt.Errorf("failed to write link graph: %w", err)
+		}
+		fmt.Fprintf(app.progress(), "🕸  Graphe des liens : %d liens écrits dans %s\n", len(edges), crawl.Graph)
+	}
+	if crawl.Report == "" {
+		return nil
+	}
+
+	fmt.Fprintf(app.progress(), "🔎 Vérification des liens...\n")
+	report := app.checkLinks(ctx, edges, workers)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := io.WriteLinkReportContext(ctx, crawl.Report, report); err != nil {
+		return fmt.Errorf("failed to write link report: %w", err)
+	}
+	fmt.Fprintf(app.progress(), "✅ %d liens vérifiés, %d cassés : rapport écrit dans %s\n", report.Checked, report.Broken, crawl.Report)
+	return nil
+}
+
+// crawlLevel traite les pages d'un niveau avec au plus workers requêtes simultanées.
+// done est appelée pour chaque page dès qu'elle est traitée, depuis une seule goroutine ;
+// les résultats sont retournés dans l'ordre des pages.
+func (app *App) crawlLevel(ctx context.Context, level []neturl.FrontierEntry, workers int, scope crawlScope, done func(crawledPage)) []crawledPage {
+	results := make([]crawledPage, len(level))
+	jobs := make(chan int)
+	finished := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(level); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = app.crawlOne(ctx, level[i], scope)
+				finished <- i
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range level {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				for j := i; j < len(level); j++ {
+					results[j] = crawledPage{page: level[j], err: ctx.Err()}
+				}
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	for i := range finished {
+		done(results[i])
+	}
+	return results
+}
+
+// crawlOne récupère une page, applique les sélecteurs et relève ses liens dans la portée.
+func (app *App) crawlOne(ctx context.Context, page neturl.FrontierEntry, scope crawlScope) crawledPage {
+	result := crawledPage{page: page}
+	doc, meta, err := app.fetchPage(ctx, page.URL)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	result.final = meta.FinalURL
+	result.doc = io.DocumentResult{
+		URL:       app.pageIdentity(meta.FinalURL, meta.Canonical),
+		Canonical: meta.Canonical,
+		Depth:     page.Depth,
+		Results:   extractUsingSelectors(doc, app.config.Selectors),
+	}
+	result.links, result.edges = app.crawlLinks(doc, page.URL, meta.FinalURL, scope)
+	for i := range result.edges {
+		result.edges[i].Source = result.doc.URL
+	}
+	return result
+}
+
+// crawlLinks retourne les liens du document résolus en URLs absolues, sans fragment,
+// limités à la portée du crawl et aux règles -include/-exclude, ainsi que tous ses liens
+// HTTP(S) sous forme d'arêtes (sans source), externes quand ils quittent le site de la page.
+func (app *App) crawlLinks(doc *htmlparser.Node, requested, finalURL string, scope crawlScope) ([]string, []linkgraph.Edge) {
+	pageURL := finalURL
+	if requested == app.config.URL && app.config.BaseURL != "" {
+		pageURL = app.config.BaseURL
+	}
+	page, err := neturl.Parse(pageURL)
+	if err != nil {
+		return nil, nil
+	}
+	base := parser.BaseURL(doc, page)
+
+	var links []string
+	var edges []linkgraph.Edge
+	for _, link := range parser.FindLinks(doc) {
+		u, err := base.Resolve(link.Href)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		u.Fragment = ""
+		target := u.String()
+		edges = append(edges, linkgraph.Edge{Target: app.displayURL(target), Text: link.Text, Rel: link.Rel, External: u.Site() != page.Site()})
+		if scope.contains(u) && app.filter.Allows(target) {
+			links = append(links, target)
+		}
+	}
+	return links, edges
+}
+
+// crawlScope décide si une URL découverte peut être visitée.
+type crawlScope struct {
+	kind   types.CrawlScope
+	host   string // hôte de départ, en ASCII et en minuscules
+	site   string // domaine enregistrable de départ ("example.co.uk")
+	prefix string // répertoire du chemin de départ
+}
+
+// newCrawlScope construit la portée à partir de l'URL de départ.
+func newCrawlScope(kind types.CrawlScope, start *neturl.URL) crawlScope {
+	if kind == "" {
+		kind = types.ScopeHost
+	}
+	start = start.Normalize(hostRules)
+	prefix := start.Path
+	if idx := strings.LastIndex(prefix, "/"); idx >= 0 {
+		prefix = prefix[:idx+1]
+	}
+	return crawlScope{kind: kind, host: start.Host, site: start.Site(), prefix: prefix}
+}
+
+// hostRules ramènent un hôte à sa forme comparable : ASCII (punycode) et en minuscules.
+const hostRules = neturl.RuleIDNA | neturl.RuleLowercaseHost
+
+// contains retourne true si l'URL est en HTTP(S) et dans la portée.
+func (s crawlScope) contains(u *neturl.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	u = u.Normalize(hostRules)
+	host := u.Host
+	switch s.kind {
+	case types.ScopeDomain:
+		return u.Site() == s.site
+	case types.ScopePrefix:
+		return host == s.host && strings.HasPrefix(u.Path, s.prefix)
+	default:
+		return host == s.host
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+
+	"webextractor/internal/fetcher"
+	"webextractor/internal/io"
+	"webextractor/internal/linkgraph"
+	"webextractor/internal/neturl"
+)
+
+// checkLinks vérifie chaque cible distincte (forme normalisée) des liens avec au plus
+// workers requêtes simultanées. Le rapport liste les liens cassés en tête, chacun avec
+// les pages qui le référencent.
+func (app *App) checkLinks(ctx context.Context, edges []linkgraph.Edge, workers int) io.LinkReport {
+	keys := neturl.NewFrontier(app.config.Normalize)
+	index := map[string]int{}
+	var checks []io.LinkCheck
+	for _, e := range edges {
+		key := keys.Key(e.Target)
+		i, ok := index[key]
+		if !ok {
+			i = len(checks)
+			index[key] = i
+			checks = append(checks, io.LinkCheck{URL: e.Target, External: e.External, ReferencedBy: []string{}})
+		}
+		if !containsString(checks[i].ReferencedBy, e.Source) {
+			checks[i].ReferencedBy = append(checks[i].ReferencedBy, e.Source)
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(checks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				app.checkLink(ctx, &checks[i])
+			}
+		}()
+	}
+	for i := range checks {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			checks[i].Error = ctx.Err().Error()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := io.LinkReport{Checked: len(checks)}
+	sort.SliceStable(checks, func(i, j int) bool { return checks[i].Broken && !checks[j].Broken })
+	for _, c := range checks {
+		if c.Broken {
+			report.Broken++
+		}
+	}
+	report.Links = checks
+	return report
+}
+
+// checkLink vérifie une URL. Un statut 4xx ou 5xx et les échecs de connexion rendent le
+// lien cassé ; une URL interdite par robots.txt est signalée sans être considérée cassée.
+func (app *App) checkLink(ctx context.Context, check *io.LinkCheck) {
+	status, err := app.linkStatus(ctx, neturl.ASCIIURL(check.URL)) // la cible peut être affichée en Unicode
+	if err != nil {
+		check.Error = err.Error()
+		check.Broken = !errors.Is(err, fetcher.ErrDisallowedByRobots)
+		return
+	}
+	check.Status = status.StatusCode
+	check.Method = status.Method
+	check.Broken = status.StatusCode >= http.StatusBadRequest
+	if final := app.displayURL(status.FinalURL); final != check.URL {
+		check.FinalURL = final
+	}
+	for _, r := range status.Redirects {
+		check.Redirects = append(check.Redirects, io.Redirect{URL: r.URL, Status: r.StatusCode})
+	}
+}
+
+// linkStatus interroge l'URL avec le LinkChecker du client intégré (HEAD puis GET) ou,
+// pour un Fetcher fourni par WithFetcher qui n'en est pas un, avec un simple GET.
+func (app *App) linkStatus(ctx context.Context, url string) (*fetcher.LinkStatus, error) {
+	if app.checker != nil {
+		return app.checker.CheckLink(ctx, url)
+	}
+	_, meta, err := fetcher.Get(ctx, app.fetcher, url)
+	if err != nil {
+		return nil, err
+	}
+	status := &fetcher.LinkStatus{URL: url, Method: http.MethodGet, StatusCode: meta.StatusCode, FinalURL: meta.FinalURL}
+	if status.StatusCode == 0 {
+		status.StatusCode = http.StatusOK
+	}
+	return status, nil
+}
+
+// containsString retourne true si la liste contient la valeur.
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
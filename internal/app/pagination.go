@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"webextractor/internal/fetcher"
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/io"
+	"webextractor/internal/neturl"
+	"webextractor/internal/parser"
+	"webextractor/internal/types"
+)
+
+// DefaultMaxPages borne la pagination quand -next est donné sans -max-pages.
+const DefaultMaxPages = 20
+
+// extractPages applique les sélecteurs à la page de départ puis à chaque page suivante
+// (lien -next, ou rel="next" à défaut) jusqu'à -max-pages, en s'arrêtant sur une boucle
+// ou un lien écarté par les règles -include/-exclude.
+// Les résultats sont fusionnés par sélecteur, chaque correspondance étant attribuée à sa page.
+func (app *App) extractPages(ctx context.Context) (io.DocumentResult, error) {
+	maxPages := app.config.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+
+	result := io.DocumentResult{URL: app.config.URL}
+	merged := make([]io.Result, 0, len(app.config.Selectors))
+	visited := neturl.NewFrontier(app.config.Normalize)
+
+	current := app.config.URL
+	for page := 1; ; page++ {
+		if page > 1 {
+			fmt.Printf("📄 Page %d : %s\n", page, current)
+		}
+		doc, meta, err := app.fetchPage(ctx, current)
+		if page > 1 && errors.Is(err, fetcher.ErrBudgetExhausted) {
+			// Les pages déjà parcourues sont conservées et signalées comme partielles
+			printPartial(os.Stdout, err)
+			result.Partial = true
+			result.StopReason = err.Error()
+			break
+		}
+		if err != nil {
+			return io.DocumentResult{}, fmt.Errorf("page %d (%s): %w", page, current, err)
+		}
+
+		if page == 1 {
+			app.printPageMetadata(meta)
+			result.URL = app.pageIdentity(app.config.URL, meta.Canonical)
+			result.Canonical = meta.Canonical
+		}
+
+		pageURL := meta.FinalURL
+		visited.MarkSeen(current)
+		visited.MarkSeen(pageURL)
+		identity := app.pageIdentity(pageURL, meta.Canonical)
+		result.Pages = append(result.Pages, identity)
+		merged = mergeResults(merged, extractUsingSelectors(doc, app.config.Selectors), identity)
+
+		if page >= maxPages {
+			if parser.NextLink(doc, app.config.NextSelector) != "" {
+				fmt.Printf("⏹  Limite de %d pages atteinte\n", maxPages)
+			}
+			break
+		}
+
+		next, ok := app.nextPage(doc, current, pageURL)
+		if !ok {
+			break
+		}
+		if reason := app.filter.Explain(next); reason != "" {
+			fmt.Printf("⏹  Page suivante hors portée : %s (%s)\n", next, reason)
+			break
+		}
+		if visited.Seen(next) {
+			fmt.Printf("🔁 Boucle détectée : %s a déjà été parcourue\n", next)
+			break
+		}
+		current = next
+	}
+
+	result.Results = merged
+	return result, nil
+}
+
+// nextPage résout le lien vers la page suivante par rapport à la page courante
+// (ou à son <base href>), sans fragment. Un lien file:// n'est suivi que depuis un
+// document local : une page distante ne peut pas faire lire le disque.
+func (app *App) nextPage(doc *htmlparser.Node, requested, pageURL string) (string, bool) {
+	href := parser.NextLink(doc, app.config.NextSelector)
+	if href == "" {
+		return "", false
+	}
+
+	// -base-url ne s'applique qu'au document de départ
+	baseURL := pageURL
+	if requested == app.config.URL && app.config.BaseURL != "" {
+		baseURL = app.config.BaseURL
+	}
+	page, err := neturl.Parse(baseURL)
+	if err != nil {
+		return "", false
+	}
+	next, err := parser.BaseURL(doc, page).Resolve(href)
+	if err != nil {
+		return "", false
+	}
+	switch next.Scheme {
+	case "http", "https":
+	case "file":
+		if !types.URLString(pageURL).IsLocal() {
+			fmt.Printf("⏹  Page suivante locale ignorée depuis une page distante : %s\n", next)
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	next.Fragment = ""
+	return next.String(), true
+}
+
+// mergeResults ajoute les correspondances d'une page aux résultats fusionnés.
+func mergeResults(merged, page []io.Result, pageURL string) []io.Result {
+	for i, r := range page {
+		if i >= len(merged) {
+			merged = append(merged, io.Result{Selector: r.Selector, Matches: []string{}, URLs: []string{}})
+		}
+		for _, match := range r.Matches {
+			merged[i].Matches = append(merged[i].Matches, match)
+			merged[i].URLs = append(merged[i].URLs, pageURL)
+		}
+	}
+	return merged
+}
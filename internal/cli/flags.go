@@ -6,24 +6,121 @@ import (
 	"strings"
 	"time"
 
+	"webextractor/internal/linkgraph"
+	"webextractor/internal/neturl"
+	"webextractor/internal/strconv"
 	"webextractor/internal/types"
 )
 
 // Flags contient toutes les valeurs des paramètres de ligne de commande
 type Flags struct {
-	URL     types.URLString // URL validée
+	URL     types.URLString // URL validée (http(s), file://, chemin local ou "-")
+	BaseURL string          // URL de base pour la résolution des liens
 	Sel     string          // Sélecteur CSS brut (sera converti en SelectorList)
 	Out     types.FilePath  // Chemin de sortie sécurisé
 	Timeout time.Duration   // Timeout pour les requêtes HTTP
+
+	Deadline time.Duration // Durée maximale de toute l'exécution (0 = illimitée)
+
+	IgnoreRobots bool // Ignore les interdictions de robots.txt
+
+	Rate     float64 // Requêtes par seconde et par hôte (0 = illimité)
+	Burst    int     // Requêtes autorisées en rafale par hôte
+	MaxConns int     // Connexions simultanées par hôte (0 = illimité)
+
+	// Les secrets sont lus dans des variables d'environnement pour ne jamais
+	// apparaître dans l'historique du shell
+	AuthUser     string            // Utilisateur pour l'authentification basique
+	AuthPassword string            // Mot de passe lu depuis l'environnement
+	AuthToken    string            // Jeton bearer lu depuis l'environnement
+	AuthHost     string            // Hôte auquel envoyer les identifiants (hôte de -url par défaut)
+	LoginURL     string            // Page du formulaire de connexion
+	LoginFields  map[string]string // Champs du formulaire de connexion
+	LoginCheck   string            // Sélecteur attestant de la connexion
+
+	Method     string            // Méthode HTTP de la requête initiale
+	Fields     neturl.Values     // Champs envoyés avec la requête initiale
+	Files      map[string]string // Fichiers envoyés en multipart
+	Multipart  bool              // Encode les champs en multipart/form-data
+	JSON       string            // Corps JSON de la requête initiale
+	SubmitForm string            // Sélecteur du formulaire à soumettre
+
+	CACert             string // Bundle PEM de CA supplémentaires
+	ClientCert         string // Certificat client PEM (TLS mutuel)
+	ClientKey          string // Clé privée du certificat client
+	TLSMinVersion      string // Version TLS minimale ("1.2", "1.3")
+	InsecureSkipVerify bool   // Désactive la vérification des certificats
+
+	Safe         bool     // Mode sûr pour les URLs non fiables
+	AllowHosts   []string // Hôtes ou plages CIDR autorisés en mode sûr
+	AllowPorts   []int    // Ports autorisés en mode sûr
+	AllowSchemes []string // Schémas autorisés en mode sûr
+
+	HARRecord types.FilePath // Fichier HAR où enregistrer les échanges
+	HARReplay string         // Fichier HAR rejoué sans accès réseau
+
+	WARCRecord types.FilePath // Archive WARC où écrire les échanges
+	WARCInput  string         // Archive WARC lue à la place de -url
+
+	URLs     string            // Fichier d'URLs traitées par lot ("-" pour l'entrée standard)
+	Template *neturl.Template  // Modèle d'URL donné à -url, développé en lot
+	Params   map[string]string // Valeurs des paramètres {nom} du modèle
+
+	Crawl   bool   // Parcourt le site à partir de -url
+	Depth   int    // Profondeur maximale du crawl
+	Scope   string // Portée du crawl : host, domain ou prefix
+	Workers int    // Pages traitées en parallèle pendant le crawl
+	State   string // Répertoire des points de reprise du crawl
+	Resume  bool   // Reprend le crawl enregistré dans State
+	Graph   string // Fichier du graphe des liens (.dot, .gv, .graphml, .csv)
+	Report  string // Fichier du rapport de liens cassés
+
+	Normalize neturl.Rules // Règles de normalisation des URLs comparées
+	Include   []string     // Motifs des liens suivis (répétable)
+	Exclude   []string     // Motifs des liens jamais suivis (répétable)
+
+	Next     string // Sélecteur du lien vers la page suivante
+	MaxPages int    // Nombre maximal de pages parcourues
+
+	MaxBodySize int64         // Taille maximale d'une réponse, 0 = illimitée
+	ByteBudget  int64         // Octets téléchargés au total, 0 = illimité
+	TimeBudget  time.Duration // Durée des téléchargements, 0 = illimitée
+
+	FollowRefresh int  // Nombre maximal de meta refresh suivis
+	Canonical     bool // Identifie les pages par leur URL canonique
+	UnicodeHosts  bool // Écrit les noms d'hôte internationalisés en Unicode
 }
 
+// DefaultMaxBodySize est la taille maximale d'une réponse par défaut (10 Mo).
+const DefaultMaxBodySize = 10 << 20
+
+// Variables d'environnement lues par défaut pour l'authentification
+const (
+	EnvUser     = "WEBEXTRACTOR_USER"
+	EnvPassword = "WEBEXTRACTOR_PASSWORD"
+	EnvToken    = "WEBEXTRACTOR_TOKEN"
+)
+
 // Parse analyse les arguments de ligne de commande et retourne les valeurs des paramètres
 func Parse() (*Flags, error) {
 	defaultOut, _ := types.NewFilePath("-")
 	flags := &Flags{
-		Out:     defaultOut,
-		Timeout: 10 * time.Second,
+		Out:          defaultOut,
+		Timeout:      10 * time.Second,
+		MaxBodySize:  DefaultMaxBodySize,
+		Depth:        types.DefaultCrawlDepth,
+		Scope:        string(types.ScopeHost),
+		Workers:      types.DefaultCrawlWorkers,
+		Normalize:    neturl.DefaultRules,
+		AuthUser:     os.Getenv(EnvUser),
+		AuthPassword: os.Getenv(EnvPassword),
+		AuthToken:    os.Getenv(EnvToken),
+		LoginFields:  map[string]string{},
+		Fields:       neturl.Values{},
+		Files:        map[string]string{},
+		Params:       map[string]string{},
 	}
+	var template string // modèle d'URL, analysé une fois tous les -param lus
 
 	args := os.Args[1:] // On ignore le nom du programme
 
@@ -39,6 +136,12 @@ func Parse() (*Flags, error) {
 			if i+1 >= len(args) {
 				return nil, fmt.Errorf("-url requires a value")
 			}
+			if isHTTPTemplate(args[i+1]) {
+				template, flags.URL = args[i+1], ""
+				i++ // ignore l'argument suivant (la valeur)
+				continue
+			}
+			template = ""
 			url, err := types.NewURLString(args[i+1])
 			if err != nil {
 				return nil, fmt.Errorf("invalid URL: %w", err)
@@ -46,6 +149,38 @@ func Parse() (*Flags, error) {
 			flags.URL = url
 			i++ // ignore l'argument suivant (la valeur)
 
+		case "-urls":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-urls requires a value")
+			}
+			flags.URLs = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-param":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-param requires a value")
+			}
+			name, value, ok := strings.Cut(args[i+1], "=")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("-param expects name=values, got %s", args[i+1])
+			}
+			flags.Params[name] = value
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-base-url":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-base-url requires a value")
+			}
+			base, err := neturl.Parse(args[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid base URL: %w", err)
+			}
+			if base.Scheme != "http" && base.Scheme != "https" && base.Scheme != "file" {
+				return nil, fmt.Errorf("invalid base URL: unsupported protocol scheme %q", base.Scheme)
+			}
+			flags.BaseURL = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
 		case "-sel":
 			if i+1 >= len(args) {
 				return nil, fmt.Errorf("-sel requires a value")
@@ -68,38 +203,422 @@ func Parse() (*Flags, error) {
 			if i+1 >= len(args) {
 				return nil, fmt.Errorf("-timeout requires a value")
 			}
-			timeoutStr := args[i+1]
+			duration, err := parseDuration(args[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout: %s", args[i+1])
+			}
+			flags.Timeout = duration
+			i++ // ignore l'argument suivant (la valeur)
 
-			// On parse la durée manuellement (cas simples)
-			var duration time.Duration
+		case "-deadline":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-deadline requires a value")
+			}
+			duration, err := parseDuration(args[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid deadline: %s", args[i+1])
+			}
+			flags.Deadline = duration
+			i++ // ignore l'argument suivant (la valeur)
 
-			if strings.HasSuffix(timeoutStr, "s") {
-				// On parse les secondes
-				secondsStr := strings.TrimSuffix(timeoutStr, "s")
-				seconds := parseInt(secondsStr)
-				if seconds < 0 {
-					return nil, fmt.Errorf("invalid timeout: %s", timeoutStr)
-				}
-				duration = time.Duration(seconds) * time.Second
-			} else if strings.HasSuffix(timeoutStr, "m") {
-				// On parse les minutes
-				minutesStr := strings.TrimSuffix(timeoutStr, "m")
-				minutes := parseInt(minutesStr)
-				if minutes < 0 {
-					return nil, fmt.Errorf("invalid timeout: %s", timeoutStr)
+		case "-max-body-size", "-byte-budget":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			size, err := parseSize(args[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", arg, err)
+			}
+			if arg == "-max-body-size" {
+				flags.MaxBodySize = size
+			} else {
+				flags.ByteBudget = size
+			}
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-time-budget":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-time-budget requires a value")
+			}
+			duration, err := parseDuration(args[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid time budget: %s", args[i+1])
+			}
+			flags.TimeBudget = duration
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-ignore-robots":
+			flags.IgnoreRobots = true
+
+		case "-rate":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-rate requires a value")
+			}
+			rate, err := strconv.ParseFloat(args[i+1])
+			if err != nil || rate < 0 {
+				return nil, fmt.Errorf("invalid rate: %s", args[i+1])
+			}
+			flags.Rate = rate
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-burst":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-burst requires a value")
+			}
+			burst := parseInt(args[i+1])
+			if burst < 1 {
+				return nil, fmt.Errorf("invalid burst: %s", args[i+1])
+			}
+			flags.Burst = burst
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-max-conns":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-max-conns requires a value")
+			}
+			maxConns := parseInt(args[i+1])
+			if maxConns < 0 {
+				return nil, fmt.Errorf("invalid max-conns: %s", args[i+1])
+			}
+			flags.MaxConns = maxConns
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-auth-user":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-auth-user requires a value")
+			}
+			flags.AuthUser = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-auth-host":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-auth-host requires a value")
+			}
+			flags.AuthHost = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-auth-password-env", "-auth-token-env":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			value, ok := os.LookupEnv(args[i+1])
+			if !ok {
+				return nil, fmt.Errorf("%s: environment variable %s is not set", arg, args[i+1])
+			}
+			if arg == "-auth-password-env" {
+				flags.AuthPassword = value
+			} else {
+				flags.AuthToken = value
+			}
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-login-url":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-login-url requires a value")
+			}
+			loginURL, err := types.NewURLString(args[i+1])
+			if err != nil || loginURL.IsLocal() {
+				return nil, fmt.Errorf("invalid login URL: %s", args[i+1])
+			}
+			flags.LoginURL = loginURL.String()
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-login-field", "-login-field-env":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			name, value, ok := strings.Cut(args[i+1], "=")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("%s expects name=value, got %s", arg, args[i+1])
+			}
+			if arg == "-login-field-env" {
+				envValue, found := os.LookupEnv(value)
+				if !found {
+					return nil, fmt.Errorf("%s: environment variable %s is not set", arg, value)
 				}
-				duration = time.Duration(minutes) * time.Minute
+				value = envValue
+			}
+			flags.LoginFields[name] = value
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-login-check":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-login-check requires a value")
+			}
+			flags.LoginCheck = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-method":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-method requires a value")
+			}
+			method := strings.ToUpper(args[i+1])
+			if method == "" || strings.ContainsAny(method, " \t/") {
+				return nil, fmt.Errorf("invalid method: %s", args[i+1])
+			}
+			flags.Method = method
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-field", "-file":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			name, value, ok := strings.Cut(args[i+1], "=")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("%s expects name=value, got %s", arg, args[i+1])
+			}
+			if arg == "-field" {
+				flags.Fields.Add(name, value)
 			} else {
-				// On définit la durée par défaut en secondes si pas de suffixe
-				seconds := parseInt(timeoutStr)
-				if seconds < 0 {
-					return nil, fmt.Errorf("invalid timeout: %s", timeoutStr)
+				if value == "" {
+					return nil, fmt.Errorf("-file %s requires a file path", name)
 				}
-				duration = time.Duration(seconds) * time.Second
+				flags.Files[name] = value
+			}
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-multipart":
+			flags.Multipart = true
+
+		case "-json":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-json requires a value")
+			}
+			flags.JSON = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-submit-form":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-submit-form requires a value")
+			}
+			flags.SubmitForm = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-ca-cert", "-client-cert", "-client-key":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			if _, err := os.Stat(args[i+1]); err != nil {
+				return nil, fmt.Errorf("%s: %w", arg, err)
+			}
+			switch arg {
+			case "-ca-cert":
+				flags.CACert = args[i+1]
+			case "-client-cert":
+				flags.ClientCert = args[i+1]
+			default:
+				flags.ClientKey = args[i+1]
+			}
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-tls-min-version":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-tls-min-version requires a value")
+			}
+			switch args[i+1] {
+			case "1.0", "1.1", "1.2", "1.3":
+				flags.TLSMinVersion = args[i+1]
+			default:
+				return nil, fmt.Errorf("invalid TLS version: %s (expected 1.0, 1.1, 1.2 or 1.3)", args[i+1])
 			}
-			flags.Timeout = duration
 			i++ // ignore l'argument suivant (la valeur)
 
+		case "-insecure-skip-verify":
+			flags.InsecureSkipVerify = true
+
+		case "-har-record":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-har-record requires a value")
+			}
+			harPath, err := types.NewFilePath(args[i+1])
+			if err != nil || harPath.String() == "-" {
+				return nil, fmt.Errorf("invalid HAR path: %s", args[i+1])
+			}
+			flags.HARRecord = harPath
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-har-replay":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-har-replay requires a value")
+			}
+			if _, err := os.Stat(args[i+1]); err != nil {
+				return nil, fmt.Errorf("-har-replay: %w", err)
+			}
+			flags.HARReplay = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-warc-record":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-warc-record requires a value")
+			}
+			warcPath, err := types.NewFilePath(args[i+1])
+			if err != nil || warcPath.String() == "-" {
+				return nil, fmt.Errorf("invalid WARC path: %s", args[i+1])
+			}
+			flags.WARCRecord = warcPath
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-warc-input":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-warc-input requires a value")
+			}
+			if _, err := os.Stat(args[i+1]); err != nil {
+				return nil, fmt.Errorf("-warc-input: %w", err)
+			}
+			flags.WARCInput = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-safe":
+			flags.Safe = true
+
+		case "-allow-host":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-allow-host requires a value")
+			}
+			flags.AllowHosts = append(flags.AllowHosts, args[i+1])
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-allow-ports":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-allow-ports requires a value")
+			}
+			for _, value := range strings.Split(args[i+1], ",") {
+				port := parseInt(strings.TrimSpace(value))
+				if port < 1 || port > 65535 {
+					return nil, fmt.Errorf("invalid port: %s", value)
+				}
+				flags.AllowPorts = append(flags.AllowPorts, port)
+			}
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-allow-schemes":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-allow-schemes requires a value")
+			}
+			for _, scheme := range strings.Split(args[i+1], ",") {
+				scheme = strings.ToLower(strings.TrimSpace(scheme))
+				if scheme != "http" && scheme != "https" {
+					return nil, fmt.Errorf("invalid scheme: %s (expected http or https)", scheme)
+				}
+				flags.AllowSchemes = append(flags.AllowSchemes, scheme)
+			}
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-crawl":
+			flags.Crawl = true
+
+		case "-depth", "-workers":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			n := parseInt(args[i+1])
+			if n < 0 || (arg == "-workers" && n < 1) {
+				return nil, fmt.Errorf("invalid %s: %s", strings.TrimPrefix(arg, "-"), args[i+1])
+			}
+			if arg == "-depth" {
+				flags.Depth = n
+			} else {
+				flags.Workers = n
+			}
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-state":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-state requires a value")
+			}
+			flags.State = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-resume":
+			flags.Resume = true
+
+		case "-graph":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-graph requires a value")
+			}
+			if _, err := linkgraph.FormatFromPath(args[i+1]); err != nil {
+				return nil, err
+			}
+			flags.Graph = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-check-links":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-check-links requires a value")
+			}
+			flags.Report = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-scope":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-scope requires a value")
+			}
+			if _, err := types.ParseCrawlScope(args[i+1]); err != nil {
+				return nil, err
+			}
+			flags.Scope = strings.ToLower(args[i+1])
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-normalize":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-normalize requires a value")
+			}
+			rules, err := neturl.ParseRules(args[i+1])
+			if err != nil {
+				return nil, err
+			}
+			flags.Normalize = rules
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-include", "-exclude":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			if _, err := neturl.NewFilter([]string{args[i+1]}, nil, 0); err != nil {
+				return nil, err
+			}
+			if arg == "-include" {
+				flags.Include = append(flags.Include, args[i+1])
+			} else {
+				flags.Exclude = append(flags.Exclude, args[i+1])
+			}
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-next":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-next requires a value")
+			}
+			flags.Next = args[i+1]
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-max-pages":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-max-pages requires a value")
+			}
+			maxPages := parseInt(args[i+1])
+			if maxPages < 1 {
+				return nil, fmt.Errorf("invalid max pages: %s", args[i+1])
+			}
+			flags.MaxPages = maxPages
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-follow-refresh":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-follow-refresh requires a value")
+			}
+			refresh := parseInt(args[i+1])
+			if refresh < 0 {
+				return nil, fmt.Errorf("invalid refresh limit: %s", args[i+1])
+			}
+			flags.FollowRefresh = refresh
+			i++ // ignore l'argument suivant (la valeur)
+
+		case "-canonical":
+			flags.Canonical = true
+
+		case "-unicode-hosts":
+			flags.UnicodeHosts = true
+
 		case "-h", "-help", "--help":
 			printUsage()
 			os.Exit(0)
@@ -109,14 +628,122 @@ func Parse() (*Flags, error) {
 		}
 	}
 
+	if template != "" {
+		t, err := neturl.ParseTemplate(template, flags.Params)
+		if err != nil {
+			return nil, err
+		}
+		flags.Template = t
+	} else if len(flags.Params) > 0 {
+		return nil, fmt.Errorf("-param requires a -url template")
+	}
+
 	// On valide les flags requis si -url n'est pas présent on retourne une erreur
-	if flags.URL.String() == "" {
+	// (une archive WARC fournit elle-même les pages)
+	if flags.WARCInput != "" {
+		if flags.URL.String() != "" || flags.Template != nil {
+			return nil, fmt.Errorf("-warc-input and -url cannot be used together")
+		}
+		if flags.Sel == "" {
+			return nil, fmt.Errorf("-warc-input requires -sel")
+		}
+	} else if flags.URLs != "" || flags.Template != nil {
+		if flags.URLs != "" && (flags.URL.String() != "" || flags.Template != nil) {
+			return nil, fmt.Errorf("-urls and -url cannot be used together")
+		}
+		if flags.Sel == "" || flags.Crawl || flags.Next != "" || flags.MaxPages > 1 {
+			return nil, fmt.Errorf("-urls and URL templates require -sel and cannot be combined with -crawl, -next or -max-pages")
+		}
+	} else if flags.URL.String() == "" {
 		return nil, fmt.Errorf("required flag missing: -url")
 	}
 
+	if flags.SubmitForm != "" && (flags.JSON != "" || flags.Method != "") {
+		return nil, fmt.Errorf("-submit-form uses the form's own method and encoding, it cannot be combined with -json or -method")
+	}
+
+	if flags.HARRecord != "" && flags.HARReplay != "" {
+		return nil, fmt.Errorf("-har-record and -har-replay cannot be used together")
+	}
+
+	if (flags.ClientCert == "") != (flags.ClientKey == "") {
+		return nil, fmt.Errorf("-client-cert and -client-key must be used together")
+	}
+
+	if !flags.Safe && (len(flags.AllowHosts) > 0 || len(flags.AllowPorts) > 0 || len(flags.AllowSchemes) > 0) {
+		return nil, fmt.Errorf("-allow-host, -allow-ports and -allow-schemes require -safe")
+	}
+
+	if flags.Crawl && (flags.Sel == "" || flags.Next != "") {
+		return nil, fmt.Errorf("-crawl requires -sel and cannot be combined with -next")
+	}
+
+	if (flags.State != "" && !flags.Crawl) || (flags.Resume && flags.State == "") {
+		return nil, fmt.Errorf("-state requires -crawl and -resume requires -state")
+	}
+
+	if (flags.Graph != "" || flags.Report != "") && !flags.Crawl {
+		return nil, fmt.Errorf("-graph and -check-links require -crawl")
+	}
+
+	if (flags.Next != "" || flags.MaxPages > 1) && flags.Sel == "" {
+		return nil, fmt.Errorf("-next and -max-pages require -sel")
+	}
+
+	if flags.LoginURL == "" && (len(flags.LoginFields) > 0 || flags.LoginCheck != "") {
+		return nil, fmt.Errorf("-login-field and -login-check require -login-url")
+	}
+
 	return flags, nil
 }
 
+// parseDuration convertit une durée simple ("30s", "2m" ou un nombre de secondes).
+func parseDuration(s string) (time.Duration, error) {
+	// On parse la durée manuellement (cas simples)
+	unit := time.Second
+	value := s
+
+	if strings.HasSuffix(s, "s") {
+		// On parse les secondes
+		value = strings.TrimSuffix(s, "s")
+	} else if strings.HasSuffix(s, "m") {
+		// On parse les minutes
+		value = strings.TrimSuffix(s, "m")
+		unit = time.Minute
+	}
+
+	// On définit la durée par défaut en secondes si pas de suffixe
+	n := parseInt(value)
+	if n < 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// parseSize convertit une taille ("512", "64K", "10M", "1G") en octets.
+// Les suffixes sont des multiples de 1024.
+func parseSize(s string) (int64, error) {
+	multiplier := int64(1)
+	value := strings.TrimSuffix(strings.ToUpper(s), "B")
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+
+	n := parseInt(value)
+	if n < 0 {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	return int64(n) * multiplier, nil
+}
+
 // parseInt convertit une chaîne en entier, retourne -1 en cas d'erreur.
 func parseInt(s string) int {
 	if s == "" {
@@ -135,16 +762,134 @@ func parseInt(s string) int {
 	return result
 }
 
+// isHTTPTemplate retourne true si la valeur de -url est un modèle d'URL HTTP(S).
+func isHTTPTemplate(value string) bool {
+	return (strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")) && neturl.IsTemplate(value)
+}
+
 // printUsage affiche les informations d'aide.
 func printUsage() {
 	fmt.Printf(`Usage of %s:
   -url string
-    	URL of the web page to extract from (required)
+    	URL, file:// URL, local file path or '-' for stdin to extract from (required unless -warc-input or -urls).
+    	An http(s) URL with {…} placeholders is a template run as a batch: {1..20} or {01..20..2} ranges,
+    	{a,b,c} lists, {@file} values (one per line), {name:…} named and {name} defined by -param
+  -param name=values
+    	Values of the {name} placeholder of a -url template: a range, a comma-separated list or @file (repeatable)
+  -urls file
+    	Run -sel over every URL of the file (one per line, '-' for stdin), -workers at a time,
+    	writing one JSON line per URL; failures become error records
+  -base-url string
+    	Base URL used to resolve relative links (useful for local files and stdin)
   -sel string
     	CSS-like selector (tag, .class, #id). If omitted, interactive mode starts
   -out string
     	Output JSON file path ('-' for stdout) (default "-")
   -timeout duration
     	HTTP client timeout (default 10s)
+  -deadline duration
+    	Maximum duration of the whole run, output is left untouched when exceeded (default none)
+  -max-body-size size
+    	Maximum size of a response once decompressed, e.g. 512K, 10M; 0 = unlimited (default 10M)
+  -byte-budget size
+    	Total bytes downloaded before the run stops with partial results (default unlimited)
+  -time-budget duration
+    	Time spent downloading before the run stops with partial results (default unlimited)
+  -ignore-robots
+    	Fetch URLs even when robots.txt disallows them
+  -rate float
+    	Maximum requests per second per host (default 0, unlimited)
+  -burst int
+    	Requests allowed in a burst per host (default 1)
+  -max-conns int
+    	Maximum concurrent connections per host (default 0, unlimited)
+  -auth-user string
+    	Username for HTTP basic auth (default $WEBEXTRACTOR_USER)
+  -auth-password-env name
+    	Environment variable holding the basic auth password (default WEBEXTRACTOR_PASSWORD)
+  -auth-token-env name
+    	Environment variable holding a bearer token (default WEBEXTRACTOR_TOKEN)
+  -auth-host host
+    	Host the credentials are sent to (default the host of -url, required with -urls or a URL template)
+  -login-url string
+    	Page with a login form submitted before the extraction
+  -login-field name=value
+    	Login form field value (repeatable)
+  -login-field-env name=VAR
+    	Login form field read from an environment variable (repeatable)
+  -login-check string
+    	Selector that must match the page returned by the login form
+  -method string
+    	HTTP method of the initial request (default GET, POST when a body is given)
+  -field name=value
+    	Form field sent with the initial request, or overriding the submitted form (repeatable)
+  -file name=path
+    	File sent as a multipart part (repeatable)
+  -multipart
+    	Encode -field values as multipart/form-data instead of urlencoded
+  -json string
+    	Raw JSON body of the initial request
+  -submit-form selector
+    	Submit the <form> matched by the selector on the -url page, using -field overrides
+  -ca-cert file
+    	PEM bundle of additional certificate authorities to trust
+  -client-cert file
+    	PEM client certificate for mutual TLS (requires -client-key)
+  -client-key file
+    	PEM private key of the client certificate
+  -tls-min-version version
+    	Minimum TLS version: 1.0, 1.1, 1.2 or 1.3 (default 1.2)
+  -insecure-skip-verify
+    	Do not verify server certificates (DANGEROUS, testing only)
+  -safe
+    	Block loopback, private, link-local and cloud metadata addresses (checked when connecting, redirects included) and local files
+  -allow-host host|CIDR
+    	Host ("*.example.com" for subdomains) or address range allowed despite -safe (repeatable)
+  -allow-ports list
+    	Comma-separated ports allowed with -safe (default all)
+  -allow-schemes list
+    	Comma-separated schemes allowed with -safe: http, https (default both)
+  -har-record file
+    	Record every HTTP request and response of the run into a HAR file
+  -har-replay file
+    	Serve responses from a HAR file without network access, unrecorded URLs fail
+  -warc-record file
+    	Write request and response records to a WARC/1.1 file (gzip per record when ending in .gz)
+  -warc-input file
+    	Run -sel over every HTML response of a WARC file instead of fetching -url
+  -crawl
+    	Crawl breadth-first from -url, following links in scope, and stream one JSON line per page
+  -depth int
+    	Maximum link depth from -url when crawling (default 2)
+  -scope string
+    	Links followed when crawling: host, domain (registrable domain per the public suffix list, subdomains included) or prefix (default "host")
+  -workers int
+    	Pages processed concurrently when crawling, with -urls or a -url template (default 4)
+  -state dir
+    	Checkpoint the crawl state (visited URLs, queue, output position) into dir
+  -resume
+    	Continue the crawl checkpointed in -state without refetching done pages or duplicating output
+  -graph file
+    	Export the crawled link graph; the format follows the extension: .dot/.gv, .graphml or .csv
+  -check-links file
+    	Check every link found while crawling (HEAD, then GET) and write a JSON broken-link report
+  -normalize rules
+    	URL normalization used to detect duplicate pages and links: comma-separated host, port,
+    	fragment, sort-query, trailing-slash, tracking, dot-segments, encoding, idna, or all/none (default "all")
+  -include pattern
+    	Only follow links matching the pattern when crawling or paginating (repeatable): a glob where
+    	* matches anything, anchored on the path and query when starting with /, or re:<regexp>
+  -exclude pattern
+    	Never follow links matching the pattern (repeatable, takes precedence over -include)
+  -next selector
+    	Follow the link matched by the selector to the next page, merging results (default limit 20 pages)
+  -max-pages int
+    	Maximum number of pages followed; above 1 without -next, rel="next" links are used (default 1, 100 with -crawl)
+  -follow-refresh int
+    	Follow up to n <meta http-equiv="refresh"> redirections (default 0, disabled)
+  -canonical
+    	Identify pages by their <link rel="canonical"> URL in the output
+  -unicode-hosts
+    	Write internationalized host names in Unicode (müller.de) instead of punycode (xn--mller-kva.de)
 `, os.Args[0])
 }
@@ -0,0 +1,25 @@
+package fetcher
+
+import (
+	"net/http"
+	"strings"
+
+	"webextractor/internal/types"
+)
+
+// applyCredentials ajoute l'en-tête Authorization si la requête vise l'hôte autorisé.
+// Un jeton bearer est prioritaire sur l'authentification basique.
+func applyCredentials(req *http.Request, creds types.Credentials) {
+	if creds.IsEmpty() {
+		return
+	}
+	if creds.Host != "" && !strings.EqualFold(creds.Host, req.URL.Host) {
+		return
+	}
+
+	if creds.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.BearerToken)
+		return
+	}
+	req.SetBasicAuth(creds.Username, creds.Password)
+}
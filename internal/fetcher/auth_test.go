@@ -0,0 +1,162 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/types"
+)
+
+const okPage = `<html><body><h1>Hello</h1></body></html>`
+
+func TestFetchBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "alice" || pass != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(okPage))
+	}))
+	defer srv.Close()
+
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true})
+	if _, err := f.Fetch(srv.URL); err == nil {
+		t.Fatalf("expected 401 without credentials")
+	}
+
+	f = NewWithOptions(Options{
+		Timeout:      2 * time.Second,
+		IgnoreRobots: true,
+		Credentials:  types.Credentials{Username: "alice", Password: "s3cret"},
+	})
+	if _, err := f.Fetch(srv.URL); err != nil {
+		t.Fatalf("unexpected error with basic auth: %v", err)
+	}
+}
+
+func TestFetchBearerTokenScopedToHost(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		w.Write([]byte(okPage))
+	}))
+	defer srv.Close()
+
+	f := NewWithOptions(Options{
+		Timeout:      2 * time.Second,
+		IgnoreRobots: true,
+		Credentials:  types.Credentials{BearerToken: "tok", Host: strings.TrimPrefix(srv.URL, "http://")},
+	})
+	if _, err := f.Fetch(srv.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Bearer tok" {
+		t.Fatalf("expected bearer token, got %q", got)
+	}
+
+	f = NewWithOptions(Options{
+		Timeout:      2 * time.Second,
+		IgnoreRobots: true,
+		Credentials:  types.Credentials{BearerToken: "tok", Host: "other.example"},
+	})
+	if _, err := f.Fetch(srv.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("expected no credentials for another host, got %q", got)
+	}
+}
+
+func newLoginServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>
+			<form id="search" action="/search"><input name="q"></form>
+			<form method="post" action="session">
+				<input type="hidden" name="csrf" value="abc123">
+				<input name="username">
+				<input type="password" name="password">
+				<input type="submit" name="go" value="Log in">
+			</form>
+		</body></html>`))
+	})
+	mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("csrf") != "abc123" || r.PostForm.Get("username") != "alice" || r.PostForm.Get("password") != "s3cret" {
+			w.Write([]byte(`<html><body><p class="error">Invalid credentials</p></body></html>`))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "ok", Path: "/"})
+		http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/dashboard", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err != nil || c.Value != "ok" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.Write([]byte(`<html><body><a class="logout" href="/logout">Logout</a><h1>Private</h1></body></html>`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestLogin(t *testing.T) {
+	srv := newLoginServer(t)
+	defer srv.Close()
+
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true})
+	err := Login(context.Background(), f, types.LoginForm{
+		URL:           srv.URL + "/login",
+		Fields:        map[string]string{"username": "alice", "password": "s3cret"},
+		CheckSelector: ".logout",
+	})
+	if err != nil {
+		t.Fatalf("unexpected login error: %v", err)
+	}
+
+	// Le cookie de session est réutilisé par les requêtes suivantes
+	doc, err := f.Fetch(srv.URL + "/dashboard")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(renderText(doc), "Private") {
+		t.Fatalf("expected private page after login")
+	}
+}
+
+func TestLoginFailure(t *testing.T) {
+	srv := newLoginServer(t)
+	defer srv.Close()
+
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true})
+	err := Login(context.Background(), f, types.LoginForm{
+		URL:           srv.URL + "/login",
+		Fields:        map[string]string{"username": "alice", "password": "wrong"},
+		CheckSelector: ".logout",
+	})
+	if !errors.Is(err, ErrLoginFailed) {
+		t.Fatalf("expected ErrLoginFailed, got %v", err)
+	}
+}
+
+// renderText concatène les textes du document pour les vérifications.
+func renderText(n *htmlparser.Node) string {
+	var b strings.Builder
+	if n.Type == htmlparser.TextNode {
+		b.WriteString(n.Data)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(renderText(c))
+	}
+	return b.String()
+}
@@ -0,0 +1,194 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/types"
+)
+
+// Le Budget est aussi un LinkChecker quand le Fetcher qu'il enveloppe en est un.
+var _ LinkChecker = (*Budget)(nil)
+
+// ErrBudgetExhausted est retournée quand le budget d'octets ou de temps d'une exécution est épuisé.
+var ErrBudgetExhausted = errors.New("budget exhausted")
+
+// Budget borne le volume total téléchargé et la durée d'une exécution. Le compte à rebours
+// démarre à la première requête ; une fois le budget épuisé, chaque requête échoue avec
+// ErrBudgetExhausted. La requête en cours peut dépasser le budget d'octets d'une réponse
+// au plus (voir Options.MaxBodySize), celle qui dépasse le budget de temps est interrompue.
+// Devant un HTTPFetcher, tous les octets reçus sont décomptés : réponses en erreur ou
+// refusées, redirections et robots.txt compris ; devant un autre Fetcher, la taille des
+// documents obtenus.
+type Budget struct {
+	next     Fetcher
+	maxBytes int64         // 0 = illimité
+	maxTime  time.Duration // 0 = illimitée
+	now      func() time.Time
+
+	mu       sync.Mutex
+	started  time.Time
+	consumed int64
+}
+
+// NewBudget retourne un Budget devant next.
+func NewBudget(next Fetcher, maxBytes int64, maxTime time.Duration) *Budget {
+	return &Budget{next: next, maxBytes: maxBytes, maxTime: maxTime, now: time.Now}
+}
+
+// FetchDocument transmet la requête si le budget le permet et décompte les octets reçus.
+func (b *Budget) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	var doc *htmlparser.Node
+	var meta *types.FetchMetadata
+	err := b.spend(ctx, func(ctx context.Context) (int64, error) {
+		var err error
+		if doc, meta, err = b.next.FetchDocument(ctx, req); err != nil {
+			return 0, err
+		}
+		return meta.BodySize, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, meta, nil
+}
+
+// CheckLink vérifie le lien avec le LinkChecker enveloppé, dans les limites du budget.
+func (b *Budget) CheckLink(ctx context.Context, url string) (*LinkStatus, error) {
+	checker, ok := b.next.(LinkChecker)
+	if !ok {
+		return nil, errors.New("link checking is not supported by this fetcher")
+	}
+	var status *LinkStatus
+	err := b.spend(ctx, func(ctx context.Context) (int64, error) {
+		var err error
+		status, err = checker.CheckLink(ctx, url)
+		return 0, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// spend exécute une requête si le budget le permet, sous le budget de temps, et décompte
+// les octets reçus par le transport HTTP, ou à défaut la taille retournée par fetch.
+func (b *Budget) spend(ctx context.Context, fetch func(ctx context.Context) (int64, error)) error {
+	b.mu.Lock()
+	if b.started.IsZero() {
+		b.started = b.now()
+	}
+	b.mu.Unlock()
+
+	if err := b.Exhausted(); err != nil {
+		return err
+	}
+
+	if b.maxTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, b.started.Add(b.maxTime))
+		defer cancel()
+	}
+
+	m := &meter{}
+	size, err := fetch(context.WithValue(ctx, meterKey{}, m))
+	if m.used.Load() {
+		size = m.bytes.Load()
+	}
+	b.mu.Lock()
+	b.consumed += size
+	b.mu.Unlock()
+
+	if err != nil {
+		// L'interruption due au budget de temps est signalée comme telle
+		if exhausted := b.Exhausted(); exhausted != nil && errors.Is(err, context.DeadlineExceeded) {
+			return exhausted
+		}
+		return err
+	}
+	return nil
+}
+
+// Exhausted retourne une erreur ErrBudgetExhausted décrivant le budget épuisé, ou nil.
+func (b *Budget) Exhausted() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.maxBytes > 0 && b.consumed >= b.maxBytes {
+		return fmt.Errorf("%w: %d bytes downloaded (limit %d)", ErrBudgetExhausted, b.consumed, b.maxBytes)
+	}
+	if b.maxTime > 0 && !b.started.IsZero() && b.now().Sub(b.started) >= b.maxTime {
+		return fmt.Errorf("%w: time limit of %s reached", ErrBudgetExhausted, b.maxTime)
+	}
+	return nil
+}
+
+// Consumed retourne le nombre d'octets décomptés.
+func (b *Budget) Consumed() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.consumed
+}
+
+// meterKey est la clé de contexte du compteur d'octets d'une requête du Budget.
+type meterKey struct{}
+
+// meter compte les octets reçus pour une requête du Budget, sur toutes les réponses
+// lues par le client HTTP (redirections, robots.txt et erreurs compris).
+type meter struct {
+	bytes atomic.Int64
+	used  atomic.Bool // le transport HTTP a vu passer la requête
+}
+
+// meteredTransport décompte les corps des réponses dans le compteur du contexte.
+type meteredTransport struct {
+	next http.RoundTripper
+}
+
+func (t *meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	m, _ := req.Context().Value(meterKey{}).(*meter)
+	if m == nil {
+		return resp, err
+	}
+	m.used.Store(true)
+	if err != nil {
+		return resp, err
+	}
+	body := &meteredBody{ReadCloser: resp.Body, meter: m, announced: resp.ContentLength}
+	if req.Method == http.MethodHead {
+		body.announced = -1 // la réponse à HEAD n'a pas de corps
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// meteredBody ajoute au compteur chaque octet lu. Un corps fermé sans être lu entièrement
+// (statut d'erreur, contenu refusé) est décompté pour la taille annoncée par le serveur.
+type meteredBody struct {
+	io.ReadCloser
+	meter     *meter
+	announced int64 // Content-Length, -1 si inconnu
+	read      int64
+}
+
+func (b *meteredBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	b.meter.bytes.Add(int64(n))
+	return n, err
+}
+
+func (b *meteredBody) Close() error {
+	if b.announced > b.read {
+		b.meter.bytes.Add(b.announced - b.read)
+		b.read = b.announced
+	}
+	return b.ReadCloser.Close()
+}
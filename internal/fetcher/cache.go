@@ -0,0 +1,69 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/types"
+)
+
+// Cache garde en mémoire les documents obtenus par un simple GET pour ne pas les
+// récupérer deux fois. Les autres requêtes et les erreurs ne sont jamais mises en cache.
+// Les documents sont partagés entre les appelants et ne doivent pas être modifiés.
+type Cache struct {
+	next Fetcher
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry est un document mis en cache.
+type cacheEntry struct {
+	doc     *htmlparser.Node
+	meta    *types.FetchMetadata
+	expires time.Time // zéro si l'entrée n'expire pas
+}
+
+// NewCache retourne un Cache devant next. Les entrées expirent après ttl (jamais si ttl vaut 0).
+func NewCache(next Fetcher, ttl time.Duration) *Cache {
+	return &Cache{next: next, ttl: ttl, now: time.Now, entries: map[string]cacheEntry{}}
+}
+
+// FetchDocument retourne le document en cache ou le récupère avec le Fetcher suivant.
+func (c *Cache) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	method := strings.ToUpper(req.Method)
+	if (method != "" && method != http.MethodGet) || len(req.Body) > 0 {
+		return c.next.FetchDocument(ctx, req)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[req.URL]
+	if ok && !entry.expires.IsZero() && c.now().After(entry.expires) {
+		delete(c.entries, req.URL)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return entry.doc, entry.meta, nil
+	}
+
+	doc, meta, err := c.next.FetchDocument(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entry = cacheEntry{doc: doc, meta: meta}
+	if c.ttl > 0 {
+		entry.expires = c.now().Add(c.ttl)
+	}
+	c.mu.Lock()
+	c.entries[req.URL] = entry
+	c.mu.Unlock()
+	return doc, meta, nil
+}
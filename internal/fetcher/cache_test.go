@@ -0,0 +1,59 @@
+package fetcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/types"
+)
+
+// countingFetcher est un Fetcher en mémoire qui compte les requêtes reçues.
+type countingFetcher struct {
+	calls int
+}
+
+func (c *countingFetcher) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	c.calls++
+	body := "<p>" + req.URL + "</p>"
+	doc, err := htmlparser.Parse(strings.NewReader(body))
+	return doc, &types.FetchMetadata{URL: req.URL, FinalURL: req.URL, BodySize: int64(len(body))}, err
+}
+
+func TestCache(t *testing.T) {
+	next := &countingFetcher{}
+	cache := NewCache(next, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, _, err := Get(ctx, cache, "https://example.com/a"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected 1 fetch for repeated GETs, got %d", next.calls)
+	}
+
+	// Les requêtes avec corps ne sont jamais mises en cache
+	post := &types.FetchRequest{URL: "https://example.com/a", Method: "POST", Body: []byte("q=1")}
+	for i := 0; i < 2; i++ {
+		if _, _, err := cache.FetchDocument(ctx, post); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if next.calls != 3 {
+		t.Fatalf("expected POST requests to bypass the cache, got %d calls", next.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, _, err := Get(ctx, cache, "https://example.com/a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.calls != 4 {
+		t.Fatalf("expected expired entry to be fetched again, got %d calls", next.calls)
+	}
+}
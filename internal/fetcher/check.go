@@ -0,0 +1,85 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Redirect est une réponse de redirection rencontrée en suivant une URL.
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status"`
+}
+
+// LinkStatus décrit la réponse obtenue pour une URL vérifiée.
+type LinkStatus struct {
+	URL        string
+	Method     string // HEAD, ou GET si HEAD a échoué ou a été refusé
+	StatusCode int
+	FinalURL   string     // URL après redirections
+	Redirects  []Redirect // redirections suivies, dans l'ordre
+}
+
+// LinkChecker vérifie qu'une URL répond sans analyser son contenu.
+type LinkChecker interface {
+	CheckLink(ctx context.Context, url string) (*LinkStatus, error)
+}
+
+var _ LinkChecker = (*HTTPFetcher)(nil)
+
+// CheckLink envoie une requête HEAD, puis un GET si HEAD échoue ou répond par une
+// erreur (certains serveurs le refusent), et retourne le statut final et les redirections
+// suivies. Un statut d'erreur n'est pas une erreur : seuls les échecs de connexion, de
+// configuration ou un refus de robots.txt en sont. Le corps de la réponse n'est pas lu.
+func (f *HTTPFetcher) CheckLink(ctx context.Context, url string) (*LinkStatus, error) {
+	if isLocal(url) {
+		return nil, errors.New("only HTTP(S) links can be checked")
+	}
+	if !f.ignoreRobots {
+		if err := f.checkRobots(ctx, url); err != nil {
+			return nil, err
+		}
+	}
+
+	status, err := f.check(ctx, http.MethodHead, url)
+	if err == nil && status.StatusCode < http.StatusBadRequest {
+		return status, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return f.check(ctx, http.MethodGet, url)
+}
+
+// check envoie une requête et décrit la réponse sans en lire le corps.
+func (f *HTTPFetcher) check(ctx context.Context, method, url string) (*LinkStatus, error) {
+	req, err := f.newRequest(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.do(req)
+	if err != nil {
+		return nil, err
+	}
+	// Quelques octets sont lus pour que la connexion puisse être réutilisée
+	_, _ = io.CopyN(io.Discard, resp.Body, 4<<10)
+	resp.Body.Close()
+
+	status := &LinkStatus{URL: url, Method: method, StatusCode: resp.StatusCode, FinalURL: url}
+	if resp.Request != nil {
+		status.FinalURL = resp.Request.URL.String()
+		status.Redirects = redirectChain(resp.Request)
+	}
+	return status, nil
+}
+
+// redirectChain reconstitue les redirections qui ont mené à la requête finale.
+func redirectChain(final *http.Request) []Redirect {
+	var chain []Redirect
+	for r := final; r.Response != nil && r.Response.Request != nil; r = r.Response.Request {
+		chain = append([]Redirect{{URL: r.Response.Request.URL.String(), StatusCode: r.Response.StatusCode}}, chain...)
+	}
+	return chain
+}
@@ -0,0 +1,71 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckLink(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/gzip":
+			// Content-Encoding annoncé sur HEAD, sans corps
+			w.Header().Set("Content-Encoding", "gzip")
+			if r.Method == http.MethodHead {
+				w.Header().Set("Content-Length", "120")
+				return
+			}
+			w.Write([]byte("not gzip"))
+		case "/no-head":
+			methods = append(methods, r.Method)
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Write([]byte("ok"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	f := NewWithOptions(Options{Timeout: time.Second, IgnoreRobots: true})
+
+	status, err := f.CheckLink(context.Background(), srv.URL+"/old")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.StatusCode != http.StatusOK || status.Method != http.MethodHead || status.FinalURL != srv.URL+"/ok" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if len(status.Redirects) != 2 || status.Redirects[0] != (Redirect{URL: srv.URL + "/old", StatusCode: 301}) || status.Redirects[1].StatusCode != 302 {
+		t.Errorf("unexpected redirect chain: %+v", status.Redirects)
+	}
+
+	status, err = f.CheckLink(context.Background(), srv.URL+"/gzip")
+	if err != nil || status.StatusCode != http.StatusOK || status.Method != http.MethodHead {
+		t.Errorf("expected HEAD to succeed on a gzip-encoded resource, got %+v (%v)", status, err)
+	}
+
+	status, err = f.CheckLink(context.Background(), srv.URL+"/no-head")
+	if err != nil || status.StatusCode != http.StatusOK || status.Method != http.MethodGet {
+		t.Errorf("expected a GET fallback, got %+v (%v)", status, err)
+	}
+	if len(methods) != 2 {
+		t.Errorf("expected HEAD then GET, got %v", methods)
+	}
+
+	status, err = f.CheckLink(context.Background(), srv.URL+"/missing")
+	if err != nil || status.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 status, got %+v (%v)", status, err)
+	}
+}
@@ -0,0 +1,155 @@
+package fetcher
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/neturl"
+	"webextractor/internal/parser"
+)
+
+// ErrUnsupportedContentType est retournée quand la réponse n'est ni du HTML ni du XML.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// sniffLen est le nombre d'octets examinés pour deviner le type du contenu.
+const sniffLen = 512
+
+// DocumentKind indique comment un document doit être analysé.
+type DocumentKind int
+
+const (
+	KindHTML DocumentKind = iota
+	KindXML               // XML, RSS, Atom, sitemaps et XHTML
+)
+
+// String retourne la représentation string
+func (k DocumentKind) String() string {
+	if k == KindXML {
+		return "xml"
+	}
+	return "html"
+}
+
+// ParseDocument détermine le type du contenu puis l'analyse en mode HTML ou XML.
+// Le contenu non balisé (JSON, PDF, images...) est rejeté avec ErrUnsupportedContentType.
+func ParseDocument(ctx context.Context, contentType string, body io.Reader) (*htmlparser.Node, error) {
+	br := bufio.NewReaderSize(body, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	kind, err := classifyContent(contentType, head)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc *htmlparser.Node
+	if kind == KindXML {
+		doc, err = htmlparser.ParseXMLContext(ctx, br)
+	} else {
+		doc, err = htmlparser.ParseContext(ctx, br)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s parse error: %w", kind, err)
+	}
+	if doc == nil {
+		return nil, errors.New("empty document")
+	}
+	return doc, nil
+}
+
+// classifyContent décide du mode d'analyse à partir du Content-Type déclaré et des premiers octets.
+func classifyContent(contentType string, head []byte) (DocumentKind, error) {
+	mediaType := ""
+	if contentType != "" {
+		if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+			mediaType = strings.ToLower(parsed)
+		}
+	}
+	sniffed := http.DetectContentType(head)
+
+	// Un type déclaré balisé est accepté sauf si le contenu est manifestement binaire
+	switch {
+	case mediaType == "text/html":
+		if isBinary(sniffed) {
+			return 0, fmt.Errorf("%w: declared %s but content looks like %s", ErrUnsupportedContentType, mediaType, sniffed)
+		}
+		// Comme dans un navigateur, text/html est analysé en HTML, même s'il se dit XHTML
+		return KindHTML, nil
+
+	case isXMLMediaType(mediaType):
+		if isBinary(sniffed) {
+			return 0, fmt.Errorf("%w: declared %s but content looks like %s", ErrUnsupportedContentType, mediaType, sniffed)
+		}
+		return KindXML, nil
+
+	case mediaType == "" || mediaType == "text/plain" || mediaType == "application/octet-stream":
+		// Type absent ou générique : on se fie au contenu
+		if looksLikeXML(head) {
+			return KindXML, nil
+		}
+		if strings.HasPrefix(sniffed, "text/html") {
+			return KindHTML, nil
+		}
+		return 0, fmt.Errorf("%w: content looks like %s", ErrUnsupportedContentType, sniffed)
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+}
+
+// contentTypeForPath devine le Content-Type d'un fichier local d'après son extension.
+func contentTypeForPath(path string) string {
+	return mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+}
+
+// isXMLMediaType retourne true pour les types XML (application/xml, text/xml, */*+xml).
+func isXMLMediaType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// isBinary retourne true si le type deviné ne correspond pas à du texte.
+func isBinary(sniffed string) bool {
+	return !strings.HasPrefix(sniffed, "text/") && !strings.HasPrefix(sniffed, "application/xml")
+}
+
+// looksLikeXML retourne true si le contenu commence par une déclaration XML
+// ou par un élément racine typique des flux et sitemaps.
+func looksLikeXML(head []byte) bool {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) {
+		return true
+	}
+	for _, root := range []string{"<rss", "<feed", "<urlset", "<sitemapindex", "<rdf:RDF"} {
+		if bytes.HasPrefix(trimmed, []byte(root)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanonicalURL retourne l'URL absolue du <link rel="canonical"> du document, ou "".
+func CanonicalURL(doc *htmlparser.Node, pageURL string) string {
+	raw := parser.Canonical(doc)
+	if raw == "" {
+		return ""
+	}
+	page, err := neturl.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	canonical, err := parser.BaseURL(doc, page).Resolve(raw)
+	if err != nil {
+		return ""
+	}
+	return canonical.String()
+}
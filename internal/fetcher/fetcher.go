@@ -1,65 +1,276 @@
 package fetcher
 
 import (
-	"errors"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"strings"
 	"time"
 
 	"webextractor/internal/htmlparser"
 	"webextractor/internal/types"
 )
 
-// Fetcher encapsule la logique du client HTTP.
-type Fetcher struct {
-	client    *http.Client
-	userAgent types.UserAgent
+// Doer exécute une requête HTTP. *http.Client en est l'implémentation directe ;
+// l'enregistrement et le rejeu HAR (package har) l'enveloppent ou le remplacent.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
 }
 
-// New retourne un Fetcher avec le timeout donné.
-func New(timeout time.Duration) *Fetcher {
-	return &Fetcher{
-		client: &http.Client{
-			Timeout: timeout,
-		},
-		userAgent: types.DefaultUserAgent,
-	}
+// Fetcher récupère et analyse des documents. HTTPFetcher, FileFetcher, Cache et le
+// rejeu HAR (NewReplay) l'implémentent ; une application peut fournir le sien
+// (cache, double de test en mémoire, client à requêtes signées...).
+type Fetcher interface {
+	// FetchDocument exécute la requête (GET si req.Method est vide) et retourne
+	// le document analysé et la description de sa récupération, non nil en l'absence
+	// d'erreur.
+	FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error)
+}
+
+// Les implémentations fournies par le package
+var (
+	_ Fetcher = (*HTTPFetcher)(nil)
+	_ Fetcher = (*FileFetcher)(nil)
+	_ Fetcher = (*Cache)(nil)
+	_ Fetcher = (*MetaRefresh)(nil)
+	_ Fetcher = (*Budget)(nil)
+)
+
+// Get récupère l'URL avec un simple GET à travers n'importe quel Fetcher.
+func Get(ctx context.Context, f Fetcher, url string) (*htmlparser.Node, *types.FetchMetadata, error) {
+	return f.FetchDocument(ctx, &types.FetchRequest{URL: url, Method: http.MethodGet})
+}
+
+// HTTPFetcher encapsule la logique du client HTTP.
+// Les documents locaux (file:// et "-") sont délégués à un FileFetcher.
+type HTTPFetcher struct {
+	doer         Doer // client HTTP, ou l'exécutant construit par Options.Doer
+	userAgent    types.UserAgent
+	ignoreRobots bool
+	robots       *robotsCache
+	limiter      *rateLimiter
+	credentials  types.Credentials
+	err          error // erreur de configuration retournée par chaque requête
+	files        *FileFetcher
+	safe         *guard // nil hors mode sûr
+	maxBodySize  int64  // taille maximale d'une réponse, 0 = illimitée
+}
+
+// Options regroupe les paramètres de construction d'un HTTPFetcher.
+type Options struct {
+	Timeout      time.Duration
+	UserAgent    types.UserAgent // types.DefaultUserAgent si vide
+	IgnoreRobots bool            // désactive la vérification de robots.txt
+
+	RequestsPerSecond float64 // débit maximal par hôte, 0 = illimité
+	Burst             int     // requêtes autorisées en rafale par hôte (1 par défaut)
+	MaxConnsPerHost   int     // connexions simultanées par hôte, 0 = illimité
+
+	Credentials types.Credentials // authentification basique ou bearer
+	TLS         types.TLSConfig   // CA supplémentaires, certificat client, version minimale
+	Safe        types.SafeMode    // restrictions des destinations pour les URLs non fiables
+
+	MaxBodySize int64 // taille maximale d'une réponse décompressée en octets, 0 = illimitée
+
+	// Doer construit l'exécutant des requêtes à partir du client HTTP configuré :
+	// il peut l'envelopper (enregistrement) ou le remplacer (rejeu). nil = client direct.
+	Doer func(client Doer) Doer
+}
+
+// New retourne un HTTPFetcher avec le timeout donné.
+func New(timeout time.Duration) *HTTPFetcher {
+	return NewWithOptions(Options{Timeout: timeout})
+}
+
+// NewWithUserAgent retourne un HTTPFetcher avec un User-Agent personnalisé.
+func NewWithUserAgent(timeout time.Duration, userAgent types.UserAgent) *HTTPFetcher {
+	return NewWithOptions(Options{Timeout: timeout, UserAgent: userAgent})
 }
 
-// NewWithUserAgent retourne un Fetcher avec un User-Agent personnalisé.
-func NewWithUserAgent(timeout time.Duration, userAgent types.UserAgent) *Fetcher {
-	return &Fetcher{
-		client: &http.Client{
-			Timeout: timeout,
-		},
-		userAgent: userAgent,
+// NewWithOptions retourne un HTTPFetcher configuré avec les options données.
+func NewWithOptions(opts Options) *HTTPFetcher {
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = types.DefaultUserAgent
 	}
+	// Le cookie jar conserve la session entre les requêtes (connexion par formulaire)
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{
+		Timeout: opts.Timeout,
+		Jar:     jar,
+	}
+	f := &HTTPFetcher{
+		userAgent:    userAgent,
+		ignoreRobots: opts.IgnoreRobots,
+		robots:       newRobotsCache(),
+		limiter:      newRateLimiter(opts.RequestsPerSecond, opts.Burst, opts.MaxConnsPerHost),
+		credentials:  opts.Credentials,
+		files:        NewFileFetcher(os.Stdin),
+		maxBodySize:  opts.MaxBodySize,
+	}
+
+	// Une configuration TLS invalide fait échouer chaque requête avec ErrInvalidTLSConfig
+	tlsConfig, err := newTLSConfig(opts.TLS)
+	if err != nil {
+		f.err = err
+	}
+	if opts.Safe.Enabled {
+		if f.safe, err = newGuard(opts.Safe); err != nil && f.err == nil {
+			f.err = err
+		}
+	}
+	// La décompression est faite par decompressor, qui surveille le taux de compression
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableCompression = true
+	transport.TLSClientConfig = tlsConfig
+	if f.safe != nil {
+		// Un proxy contacterait la destination à notre place, sans vérification
+		transport.Proxy = nil
+		transport.DialContext = f.safe.dialContext
+	}
+	client.CheckRedirect = f.checkRedirect
+	client.Transport = &meteredTransport{next: transport}
+
+	// La taille maximale s'applique au corps décompressé, avant tout enregistrement
+	f.doer = &bodyLimiter{next: &decompressor{next: client}, max: opts.MaxBodySize}
+	if opts.Doer != nil {
+		f.doer = opts.Doer(f.doer)
+	}
+	return f
 }
 
 // Fetch récupère la page située à l'URL et analyse le corps comme HTML.
-func (f *Fetcher) Fetch(url string) (*htmlparser.Node, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+// C'est un raccourci pour FetchContext avec context.Background().
+func (f *HTTPFetcher) Fetch(url string) (*htmlparser.Node, error) {
+	return f.FetchContext(context.Background(), url)
+}
+
+// FetchContext récupère la page située à l'URL et analyse le corps comme HTML ou XML
+// selon le Content-Type et les premiers octets ; tout autre contenu est refusé avec
+// ErrUnsupportedContentType. Les URLs file:// et "-" (entrée standard) sont lues localement.
+// L'URL, comme chaque redirection suivie, est refusée avec ErrDisallowedByRobots si
+// robots.txt l'interdit.
+// L'annulation du contexte interrompt l'attente, la requête et l'analyse.
+func (f *HTTPFetcher) FetchContext(ctx context.Context, url string) (*htmlparser.Node, error) {
+	doc, _, err := f.FetchWithMetadata(ctx, url)
+	return doc, err
+}
+
+// FetchWithMetadata fonctionne comme FetchContext et décrit aussi la récupération :
+// URL finale, statut, Content-Type et, en HTTPS, la connexion TLS négociée.
+func (f *HTTPFetcher) FetchWithMetadata(ctx context.Context, url string) (*htmlparser.Node, *types.FetchMetadata, error) {
+	return Get(ctx, f, url)
+}
+
+// FetchDocument envoie une requête arbitraire (méthode et corps quelconques) et analyse la réponse.
+func (f *HTTPFetcher) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	if isLocal(req.URL) {
+		if f.safe != nil {
+			return nil, nil, &UnsafeDestinationError{URL: req.URL, Reason: "local documents not allowed"}
+		}
+		return f.files.FetchDocument(ctx, req)
+	}
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if len(req.Body) > 0 {
+		body = bytes.NewReader(req.Body)
+	}
+	return f.fetchHTTP(ctx, method, req.URL, req.ContentType, body)
+}
+
+// fetchHTTP envoie une requête après vérification de robots.txt et analyse la réponse.
+func (f *HTTPFetcher) fetchHTTP(ctx context.Context, method, url, contentType string, body io.Reader) (*htmlparser.Node, *types.FetchMetadata, error) {
+	req, err := f.newRequest(ctx, method, url, body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
 	}
-	req.Header.Set("User-Agent", f.userAgent.String())
 
-	resp, err := f.client.Do(req)
+	if !f.ignoreRobots {
+		if err := f.checkRobots(ctx, url); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	resp, err := f.do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+		return nil, nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+	if f.maxBodySize > 0 && resp.ContentLength > f.maxBodySize {
+		return nil, nil, fmt.Errorf("%w: %d bytes announced, limit %d", ErrBodyTooLarge, resp.ContentLength, f.maxBodySize)
+	}
+	raw, err := readBody(resp.Body, f.maxBodySize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &types.FetchMetadata{
+		URL:         url,
+		FinalURL:    resp.Request.URL.String(),
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		TLS:         tlsInfo(resp.TLS),
+		BodySize:    int64(len(raw)),
 	}
 
-	doc, err := htmlparser.Parse(resp.Body)
+	doc, err := ParseDocument(ctx, meta.ContentType, bytes.NewReader(raw))
 	if err != nil {
-		return nil, fmt.Errorf("html parse error: %w", err)
+		return nil, nil, err
 	}
-	if doc == nil {
-		return nil, errors.New("empty document")
+	meta.Canonical = CanonicalURL(doc, meta.FinalURL)
+	return doc, meta, nil
+}
+
+// newRequest prépare une requête avec le User-Agent et les identifiants configurés.
+func (f *HTTPFetcher) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if f.safe != nil {
+		if err := f.safe.checkURL(req); err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Set("User-Agent", f.userAgent.String())
+	applyCredentials(req, f.credentials)
+	return req, nil
+}
+
+// do exécute la requête en respectant les limites de débit et de connexions de l'hôte,
+// et de chaque hôte atteint par redirection (voir checkRedirect). La connexion réservée
+// est libérée à la fermeture du corps de la réponse.
+func (f *HTTPFetcher) do(req *http.Request) (*http.Response, error) {
+	release, err := f.limiter.acquire(req.Context(), limiterKey(req))
+	if err != nil {
+		return nil, err
+	}
+	hold := &limiterHold{release: release}
+	req = req.WithContext(context.WithValue(req.Context(), limiterHoldKey{}, hold))
+
+	resp, err := f.doer.Do(req)
+	if err != nil {
+		hold.swap(nil)
+		return nil, err
 	}
-	return doc, nil
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: func() { hold.swap(nil) }}
+	return resp, nil
 }
@@ -1,10 +1,18 @@
 package fetcher
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/types"
 )
 
 func TestFetch(t *testing.T) {
@@ -77,3 +85,237 @@ func TestFetchUserAgent(t *testing.T) {
 		t.Fatalf("expected User-Agent '%s', got '%s'", expectedUA, userAgent)
 	}
 }
+
+func TestFetchDisallowedByRobots(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: WebExtractor\nDisallow: /private\n"))
+			return
+		}
+		if r.URL.Path == "/go" {
+			http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+			return
+		}
+		w.Write([]byte(`<html><body><h1>Hello</h1></body></html>`))
+	}))
+	defer srv.Close()
+
+	f := New(5 * time.Second)
+	if _, err := f.Fetch(srv.URL + "/public"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := f.Fetch(srv.URL + "/private/page")
+	if !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("expected ErrDisallowedByRobots, got %v", err)
+	}
+
+	// Une redirection vers un chemin interdit est refusée
+	_, err = f.Fetch(srv.URL + "/go?to=/private/page")
+	if !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("expected redirect target to be checked against robots.txt, got %v", err)
+	}
+
+	f = NewWithOptions(Options{Timeout: 5 * time.Second, IgnoreRobots: true})
+	if _, err := f.Fetch(srv.URL + "/private/page"); err != nil {
+		t.Fatalf("expected override to allow fetch, got %v", err)
+	}
+}
+
+func TestFetchRobotsServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`<html><body><h1>Hello</h1></body></html>`))
+	}))
+	defer srv.Close()
+
+	f := New(5 * time.Second)
+	if _, err := f.Fetch(srv.URL); !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("expected 5xx robots.txt to disallow everything, got %v", err)
+	}
+}
+
+func TestFetchLocalFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "my page.html")
+	if err := os.WriteFile(path, []byte(`<html><body><h1>Local</h1></body></html>`), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	f := New(2 * time.Second)
+	doc, err := f.Fetch("file://" + strings.ReplaceAll(path, " ", "%20"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc == nil || doc.FirstChild == nil {
+		t.Fatalf("expected parsed document")
+	}
+
+	if _, err := f.Fetch("file://" + filepath.Join(dir, "missing.html")); err == nil {
+		t.Fatalf("expected error for missing file")
+	}
+}
+
+func TestFetchStdin(t *testing.T) {
+	f := New(2 * time.Second)
+	f.files = NewFileFetcher(strings.NewReader(`<html><body><p>From stdin</p></body></html>`))
+
+	for i := 0; i < 2; i++ {
+		doc, err := f.Fetch("-")
+		if err != nil {
+			t.Fatalf("unexpected error on read %d: %v", i, err)
+		}
+		if doc.FirstChild == nil {
+			t.Fatalf("expected stdin document to be reusable (read %d)", i)
+		}
+	}
+}
+
+func TestFetchContextCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		w.Write([]byte(`<html><body><h1>Slow</h1></body></html>`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	f := New(5 * time.Second)
+	start := time.Now()
+	_, err := f.FetchContext(ctx, srv.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("cancellation took too long: %v", elapsed)
+	}
+}
+
+func TestFetchContentTypeGating(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{"html", "text/html; charset=utf-8", `<html><body><h1>Hello</h1></body></html>`, false},
+		{"json", "application/json", `{"hello": "world"}`, true},
+		{"pdf declared as html", "text/html", "%PDF-1.7\n\x00\x01\x02binary", true},
+		{"image", "image/png", "\x89PNG\r\n\x1a\n\x00\x00", true},
+		{"rss", "application/rss+xml", `<?xml version="1.0"?><rss><channel><title>Feed</title></channel></rss>`, false},
+		{"untyped xml", "", `<?xml version="1.0"?><urlset><url><loc>https://example.com/</loc></url></urlset>`, false},
+		{"untyped text", "text/plain", `just some words`, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/robots.txt" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Type", tc.contentType)
+				w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+
+			_, err := New(2 * time.Second).Fetch(srv.URL)
+			if tc.wantErr && !errors.Is(err, ErrUnsupportedContentType) {
+				t.Fatalf("expected ErrUnsupportedContentType, got %v", err)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestXHTMLServedAsHTML(t *testing.T) {
+	page := `<?xml version="1.0"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><body><p>One<br><p>Two &nbsp; & more</body></html>`
+	if kind, err := classifyContent("text/html", []byte(page)); err != nil || kind != KindHTML {
+		t.Fatalf("expected text/html to be parsed as HTML, got %v (%v)", kind, err)
+	}
+	if kind, err := classifyContent("application/xhtml+xml", []byte(page)); err != nil || kind != KindXML {
+		t.Fatalf("expected application/xhtml+xml to be parsed as XML, got %v (%v)", kind, err)
+	}
+}
+
+func TestFetchXMLPreservesCase(t *testing.T) {
+	feed := `<?xml version="1.0"?>
+<rss xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <item>
+      <title>First</title>
+      <link>https://example.com/first</link>
+      <pubDate>Mon, 01 Jan 2024 00:00:00 GMT</pubDate>
+      <dc:creator><![CDATA[Ann <Admin>]]></dc:creator>
+    </item>
+  </channel>
+</rss>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(feed))
+	}))
+	defer srv.Close()
+
+	doc, err := New(2 * time.Second).Fetch(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	var creatorNS, link string
+	var walk func(n *htmlparser.Node)
+	walk = func(n *htmlparser.Node) {
+		if n.Type == htmlparser.ElementNode {
+			names = append(names, n.Data)
+			if n.Data == "dc:creator" {
+				creatorNS = n.Namespace
+			}
+			if n.Data == "link" && n.FirstChild != nil {
+				link = n.FirstChild.Data
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	if !strings.Contains(strings.Join(names, ","), "pubDate") {
+		t.Errorf("expected element case to be preserved, got %v", names)
+	}
+	if creatorNS != "http://purl.org/dc/elements/1.1/" {
+		t.Errorf("expected dc namespace, got %q", creatorNS)
+	}
+	if link != "https://example.com/first" {
+		t.Errorf("expected <link> to keep its text content in XML mode, got %q", link)
+	}
+}
+
+func TestFileFetcherRejectsRemote(t *testing.T) {
+	f := NewFileFetcher(strings.NewReader(""))
+	if _, _, err := Get(context.Background(), f, "https://example.com/"); err == nil {
+		t.Fatalf("expected error for a remote URL")
+	}
+	req := &types.FetchRequest{URL: "-", Method: "POST", Body: []byte("x")}
+	if _, _, err := f.FetchDocument(context.Background(), req); err == nil {
+		t.Fatalf("expected error for a local POST")
+	}
+}
@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/neturl"
+	"webextractor/internal/parser"
+	"webextractor/internal/types"
+)
+
+// ErrFormNotFound est retournée quand aucun formulaire ne correspond au sélecteur.
+var ErrFormNotFound = errors.New("form not found")
+
+// SubmitForm récupère la page, y trouve le <form> désigné par le sélecteur (ou celui qui
+// contient l'élément trouvé) puis le soumet comme un navigateur : action résolue par
+// rapport à la page, méthode et encodage du formulaire, champs par défaut et cachés
+// conservés. Les valeurs données remplacent celles du formulaire, les fichiers sont
+// envoyés dans les champs correspondants.
+func SubmitForm(ctx context.Context, f Fetcher, pageURL, selector string, values neturl.Values, files map[string]string) (*htmlparser.Node, *types.FetchMetadata, error) {
+	doc, meta, err := Get(ctx, f, pageURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	form, ok := findForm(doc, selector)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %q on %s", ErrFormNotFound, selector, meta.FinalURL)
+	}
+
+	return submit(ctx, f, form, meta.FinalURL, values, files)
+}
+
+// submit envoie un formulaire déjà analysé et retourne la page obtenue.
+func submit(ctx context.Context, f Fetcher, form parser.Form, pageURL string, overrides neturl.Values, files map[string]string) (*htmlparser.Node, *types.FetchMetadata, error) {
+	values := form.Values()
+	for name, vs := range overrides {
+		values[name] = vs
+	}
+
+	base, err := neturl.Parse(pageURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	action, err := base.Resolve(form.Action)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid form action %q: %w", form.Action, err)
+	}
+	// Le fragment n'est jamais envoyé au serveur
+	action.Fragment = ""
+
+	if form.Method == http.MethodGet {
+		action.RawQuery = values.Encode()
+		return Get(ctx, f, action.String())
+	}
+
+	req := &types.FetchRequest{URL: action.String(), Method: http.MethodPost, ContentType: ContentTypeForm}
+	if form.Enctype == "multipart/form-data" || len(files) > 0 {
+		// Les champs fichier non remplis sont envoyés vides
+		withFiles := map[string]string{}
+		for _, field := range form.Fields {
+			if field.Type == "file" {
+				withFiles[field.Name] = ""
+			}
+		}
+		for name, path := range files {
+			withFiles[name] = path
+		}
+		encoded, multipartType, err := EncodeMultipart(values, withFiles)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Body = encoded
+		req.ContentType = multipartType
+	} else {
+		req.Body = []byte(values.Encode())
+	}
+
+	return f.FetchDocument(ctx, req)
+}
+
+// findForm retourne le formulaire désigné par le sélecteur : l'élément lui-même
+// s'il s'agit d'un <form>, sinon son formulaire parent.
+func findForm(doc *htmlparser.Node, selector string) (parser.Form, bool) {
+	for _, n := range parser.FindAll(doc, selector) {
+		for cur := n; cur != nil; cur = cur.Parent {
+			if cur.Type == htmlparser.ElementNode && cur.Data == "form" {
+				return parser.ParseForm(cur), true
+			}
+		}
+	}
+	return parser.Form{}, false
+}
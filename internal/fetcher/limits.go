@@ -0,0 +1,156 @@
+package fetcher
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrBodyTooLarge est retournée quand une réponse dépasse Options.MaxBodySize.
+	ErrBodyTooLarge = errors.New("response body too large")
+	// ErrDecompressionBomb est retournée quand un corps compressé se décompresse
+	// dans une proportion anormale.
+	ErrDecompressionBomb = errors.New("suspicious compression ratio")
+)
+
+const (
+	// maxCompressionRatio borne le rapport taille décompressée / taille reçue.
+	// Le HTML se compresse rarement au-delà de 20:1, une bombe dépasse 1000:1.
+	maxCompressionRatio = 100
+	// ratioCheckThreshold est la taille décompressée à partir de laquelle le rapport est vérifié,
+	// pour ne pas refuser les petites pages très répétitives.
+	ratioCheckThreshold = 1 << 20
+)
+
+// readBody lit le corps entier, en échouant avec ErrBodyTooLarge au-delà de max octets
+// (0 = illimité).
+func readBody(r io.Reader, max int64) ([]byte, error) {
+	if max <= 0 {
+		return io.ReadAll(r)
+	}
+	body, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > max {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, max)
+	}
+	return body, nil
+}
+
+// bodyLimiter applique la taille maximale aux corps des réponses au plus près du client,
+// avant les enregistreurs HAR et WARC qui lisent le corps entier : une réponse trop
+// grande échoue avec ErrBodyTooLarge sans être chargée en mémoire.
+type bodyLimiter struct {
+	next Doer
+	max  int64 // 0 = illimité
+}
+
+// Do exécute la requête et borne la lecture du corps de la réponse.
+func (l *bodyLimiter) Do(req *http.Request) (*http.Response, error) {
+	resp, err := l.next.Do(req)
+	if err != nil || l.max <= 0 {
+		return resp, err
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, max: l.max}
+	return resp, nil
+}
+
+// limitedBody échoue avec ErrBodyTooLarge, comme readBody, au-delà de max octets.
+type limitedBody struct {
+	io.ReadCloser
+	max  int64
+	read int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.max {
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, b.max)
+	}
+	if rest := b.max - b.read + 1; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.max {
+		return n, fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, b.max)
+	}
+	return n, err
+}
+
+// decompressor demande les réponses compressées en gzip et les décompresse lui-même
+// (le transport est configuré avec DisableCompression), en surveillant le taux de
+// compression. Il se place au plus près du client : les enregistrements HAR et WARC
+// voient le corps décompressé, comme avec la décompression du transport.
+type decompressor struct {
+	next Doer
+}
+
+// Do exécute la requête et remplace le corps gzip par sa version décompressée surveillée.
+func (d *decompressor) Do(req *http.Request) (*http.Response, error) {
+	requested := false
+	if req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+		requested = true
+	}
+	resp, err := d.next.Do(req)
+	if err != nil || !requested || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return resp, err
+	}
+	// Réponses sans corps : rien à décompresser
+	if req.Method == http.MethodHead || resp.StatusCode == http.StatusNoContent ||
+		resp.StatusCode == http.StatusNotModified || resp.ContentLength == 0 {
+		return resp, nil
+	}
+
+	wire := &countingReader{r: resp.Body}
+	zr, err := gzip.NewReader(wire)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	resp.Body = &ratioReader{zr: zr, wire: wire, closer: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// countingReader compte les octets lus.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ratioReader décompresse et échoue avec ErrDecompressionBomb si le taux de
+// compression devient anormal.
+type ratioReader struct {
+	zr      *gzip.Reader
+	wire    *countingReader
+	closer  io.Closer
+	decoded int64
+}
+
+func (r *ratioReader) Read(p []byte) (int, error) {
+	n, err := r.zr.Read(p)
+	r.decoded += int64(n)
+	if r.decoded > ratioCheckThreshold && r.decoded > r.wire.n*maxCompressionRatio {
+		return n, fmt.Errorf("%w: %d bytes from %d compressed", ErrDecompressionBomb, r.decoded, r.wire.n)
+	}
+	return n, err
+}
+
+func (r *ratioReader) Close() error {
+	return r.closer.Close()
+}
@@ -0,0 +1,161 @@
+package fetcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"webextractor/internal/har"
+	"webextractor/internal/parser"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestBodyLimits(t *testing.T) {
+	page := `<html><body><h1>Compressed</h1></body></html>`
+	bomb := gzipped(t, "<html><body>"+strings.Repeat(" ", 8<<20)+"</body></html>")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/gzip", "/bomb":
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				t.Errorf("expected gzip to be requested, got %q", r.Header.Get("Accept-Encoding"))
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			if r.URL.Path == "/bomb" {
+				w.Write(bomb)
+			} else {
+				w.Write(gzipped(t, page))
+			}
+		case "/large":
+			w.Write([]byte(strings.Repeat("x", 2048)))
+		case "/chunked":
+			for i := 0; i < 4; i++ {
+				w.Write([]byte(strings.Repeat("x", 512)))
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, MaxBodySize: 1024})
+
+	doc, meta, err := f.FetchWithMetadata(ctx, srv.URL+"/gzip")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h1 := parser.FindAll(doc, "h1"); len(h1) != 1 || parser.TextContent(h1[0]) != "Compressed" {
+		t.Fatalf("expected decompressed document")
+	}
+	if meta.BodySize != int64(len(page)) {
+		t.Errorf("expected decompressed size %d, got %d", len(page), meta.BodySize)
+	}
+
+	for _, path := range []string{"/large", "/chunked"} {
+		if _, err := f.FetchContext(ctx, srv.URL+path); !errors.Is(err, ErrBodyTooLarge) {
+			t.Errorf("%s: expected ErrBodyTooLarge, got %v", path, err)
+		}
+	}
+
+	unlimited := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true})
+	if _, err := unlimited.FetchContext(ctx, srv.URL+"/bomb"); !errors.Is(err, ErrDecompressionBomb) {
+		t.Fatalf("expected ErrDecompressionBomb, got %v", err)
+	}
+}
+
+func TestBudgetChargesFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/missing", http.StatusFound)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(strings.Repeat("x", 600)))
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte(strings.Repeat("x", 600)))
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	b := NewBudget(NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true}), 1000, 0)
+	if _, _, err := Get(ctx, b, srv.URL+"/moved"); err == nil {
+		t.Fatal("expected an error status")
+	}
+	if b.Consumed() < 600 {
+		t.Fatalf("expected the error response to be charged, got %d bytes", b.Consumed())
+	}
+	if _, err := b.CheckLink(ctx, srv.URL+"/missing"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := Get(ctx, b, srv.URL+"/image"); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("expected link checks to be charged, got %v (%d bytes)", err, b.Consumed())
+	}
+}
+
+func TestBodyLimitsWithRecorder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		for i := 0; i < 64; i++ {
+			w.Write([]byte(strings.Repeat("x", 1024)))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	var recorder *har.Recorder
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, MaxBodySize: 1024,
+		Doer: func(client Doer) Doer {
+			recorder = har.NewRecorder(client, har.Creator{Name: "test"})
+			return recorder
+		}})
+	if _, err := f.FetchContext(context.Background(), srv.URL); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge with a recorder, got %v", err)
+	}
+	if n := len(recorder.Entries()); n != 0 {
+		t.Errorf("expected the oversized response not to be recorded, got %d entries", n)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	next := &countingFetcher{} // "<p>a</p>" : 8 octets par page
+	ctx := context.Background()
+
+	bytesBudget := NewBudget(next, 15, 0)
+	for _, url := range []string{"a", "b"} {
+		if _, _, err := Get(ctx, bytesBudget, url); err != nil {
+			t.Fatalf("%s: unexpected error: %v", url, err)
+		}
+	}
+	if _, _, err := Get(ctx, bytesBudget, "a"); !errors.Is(err, ErrBudgetExhausted) || bytesBudget.Consumed() != 16 {
+		t.Fatalf("expected byte budget to be exhausted after 16 bytes, got %v (%d)", err, bytesBudget.Consumed())
+	}
+
+	now := time.Unix(0, 0)
+	timeBudget := NewBudget(next, 0, time.Minute)
+	timeBudget.now = func() time.Time { return now }
+	if _, _, err := Get(ctx, timeBudget, "a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, _, err := Get(ctx, timeBudget, "b"); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("expected time budget to be exhausted, got %v", err)
+	}
+}
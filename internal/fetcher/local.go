@@ -0,0 +1,94 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/neturl"
+	"webextractor/internal/types"
+)
+
+// isLocal retourne true si l'URL désigne un fichier local ou l'entrée standard.
+func isLocal(rawurl string) bool {
+	return types.URLString(rawurl).IsLocal()
+}
+
+// FileFetcher lit des documents locaux : URLs file:// et "-" pour l'entrée standard.
+// Il n'accède jamais au réseau.
+type FileFetcher struct {
+	stdin     io.Reader
+	stdinOnce sync.Once
+	stdinBody []byte
+	stdinErr  error
+}
+
+// NewFileFetcher retourne un FileFetcher qui lit le document "-" depuis stdin.
+func NewFileFetcher(stdin io.Reader) *FileFetcher {
+	return &FileFetcher{stdin: stdin}
+}
+
+// FetchDocument lit le document local désigné par la requête, qui doit être un simple GET.
+func (f *FileFetcher) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if !isLocal(req.URL) {
+		return nil, nil, fmt.Errorf("not a local document: %s", req.URL)
+	}
+	if method := strings.ToUpper(req.Method); (method != "" && method != http.MethodGet) || len(req.Body) > 0 {
+		return nil, nil, fmt.Errorf("%s with a body is not supported for local documents", method)
+	}
+
+	body, err := f.readLocal(req.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta := &types.FetchMetadata{URL: req.URL, FinalURL: req.URL, ContentType: contentTypeForPath(req.URL), BodySize: int64(len(body))}
+	doc, err := ParseDocument(ctx, meta.ContentType, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	meta.Canonical = CanonicalURL(doc, meta.FinalURL)
+	return doc, meta, nil
+}
+
+// readLocal retourne le contenu brut d'un document local.
+// L'entrée standard n'est lue qu'une fois puis gardée en mémoire.
+func (f *FileFetcher) readLocal(rawurl string) ([]byte, error) {
+	if types.URLString(rawurl).IsStdin() {
+		f.stdinOnce.Do(func() {
+			f.stdinBody, f.stdinErr = io.ReadAll(f.stdin)
+		})
+		if f.stdinErr != nil {
+			return nil, fmt.Errorf("read stdin: %w", f.stdinErr)
+		}
+		return f.stdinBody, nil
+	}
+
+	u, err := neturl.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		return nil, fmt.Errorf("remote file host not supported: %s", u.Host)
+	}
+
+	path, err := neturl.Unescape(u.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file path %q: %w", u.Path, err)
+	}
+
+	body, err := os.ReadFile(path) // #nosec G304 - lecture explicitement demandée par l'utilisateur
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,65 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"webextractor/internal/neturl"
+	"webextractor/internal/parser"
+	"webextractor/internal/types"
+)
+
+// ErrLoginFailed est retournée quand la connexion par formulaire échoue.
+var ErrLoginFailed = errors.New("login failed")
+
+// Login se connecte via un formulaire : la page de connexion est récupérée, les champs
+// nommés sont remplis, le formulaire est soumis à travers f (dont le cookie jar conserve
+// la session) puis le succès est vérifié avec login.CheckSelector sur la page obtenue.
+func Login(ctx context.Context, f Fetcher, login types.LoginForm) error {
+	doc, page, err := Get(ctx, f, login.URL)
+	if err != nil {
+		return fmt.Errorf("%w: login page: %v", ErrLoginFailed, err)
+	}
+
+	form, ok := selectLoginForm(parser.FindForms(doc), login.Fields)
+	if !ok {
+		return fmt.Errorf("%w: no login form found on %s", ErrLoginFailed, page.FinalURL)
+	}
+
+	values := neturl.Values{}
+	for name, value := range login.Fields {
+		values.Set(name, value)
+	}
+
+	// On soumet le formulaire comme le ferait un navigateur
+	result, meta, err := submit(ctx, f, form, page.FinalURL, values, nil)
+	if err != nil {
+		return fmt.Errorf("%w: submit: %v", ErrLoginFailed, err)
+	}
+
+	if login.CheckSelector != "" && len(parser.FindAll(result, login.CheckSelector)) == 0 {
+		return fmt.Errorf("%w: selector %q not found on %s", ErrLoginFailed, login.CheckSelector, meta.FinalURL)
+	}
+	return nil
+}
+
+// selectLoginForm choisit le formulaire qui contient les champs à remplir,
+// ou à défaut le premier formulaire avec un mot de passe.
+func selectLoginForm(forms []parser.Form, fields map[string]string) (parser.Form, bool) {
+	for _, form := range forms {
+		for name := range fields {
+			if form.HasField(name) {
+				return form, true
+			}
+		}
+	}
+	for _, form := range forms {
+		for _, field := range form.Fields {
+			if field.Type == "password" {
+				return form, true
+			}
+		}
+	}
+	return parser.Form{}, false
+}
@@ -0,0 +1,177 @@
+package fetcher
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiter applique, pour chaque hôte, un seau à jetons et une limite de connexions simultanées.
+// Il peut être utilisé par plusieurs goroutines en même temps.
+type rateLimiter struct {
+	rate     float64 // requêtes par seconde, 0 = illimité
+	burst    int     // nombre de requêtes autorisées en rafale
+	maxConns int     // connexions simultanées par hôte, 0 = illimité
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+// hostLimiter contient l'état du seau à jetons et le sémaphore d'un hôte.
+type hostLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	conns  chan struct{}
+}
+
+// newRateLimiter crée un limiteur. Un burst nul vaut 1.
+func newRateLimiter(rate float64, burst, maxConns int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:     rate,
+		burst:    burst,
+		maxConns: maxConns,
+		hosts:    make(map[string]*hostLimiter),
+	}
+}
+
+// acquire bloque jusqu'à ce qu'une requête vers l'hôte soit autorisée ou que le contexte soit annulé.
+// La fonction retournée libère la connexion réservée et doit toujours être appelée.
+func (l *rateLimiter) acquire(ctx context.Context, host string) (release func(), err error) {
+	h := l.host(host)
+
+	// On réserve d'abord une connexion pour ne pas consommer de jeton en attendant
+	if h.conns != nil {
+		select {
+		case h.conns <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			if h.conns != nil {
+				<-h.conns
+			}
+		})
+	}
+
+	if wait := l.reserve(h); wait > 0 {
+		if err := sleepContext(ctx, wait); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// reserve consomme un jeton et retourne le temps d'attente nécessaire avant de l'utiliser.
+func (l *rateLimiter) reserve(h *hostLimiter) time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// On remplit le seau selon le temps écoulé depuis la dernière réservation
+	now := time.Now()
+	h.tokens += now.Sub(h.last).Seconds() * l.rate
+	if h.tokens > float64(l.burst) {
+		h.tokens = float64(l.burst)
+	}
+	h.last = now
+
+	// Le solde peut devenir négatif : les requêtes suivantes attendront d'autant plus
+	h.tokens--
+	if h.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-h.tokens / l.rate * float64(time.Second))
+}
+
+// host retourne l'état de l'hôte, en le créant si nécessaire.
+func (l *rateLimiter) host(host string) *hostLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostLimiter{
+			tokens: float64(l.burst),
+			last:   time.Now(),
+		}
+		if l.maxConns > 0 {
+			h.conns = make(chan struct{}, l.maxConns)
+		}
+		l.hosts[host] = h
+	}
+	return h
+}
+
+// sleepContext attend la durée donnée ou l'annulation du contexte.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limiterKey retourne l'hôte de la requête tel que le limiteur le compte : en minuscules
+// et sans le port par défaut du schéma, pour que Example.com et example.com:443 partagent
+// le même seau.
+func limiterKey(req *http.Request) string {
+	host, port := strings.ToLower(req.URL.Hostname()), req.URL.Port()
+	if port == "" || (req.URL.Scheme == "http" && port == "80") || (req.URL.Scheme == "https" && port == "443") {
+		return host
+	}
+	return host + ":" + port
+}
+
+// limiterHoldKey est la clé de contexte de la connexion réservée par une requête.
+type limiterHoldKey struct{}
+
+// limiterHold est la connexion réservée par une requête. Chaque redirection suivie libère
+// celle de l'étape précédente et en réserve une auprès de l'hôte de destination.
+type limiterHold struct {
+	mu      sync.Mutex
+	release func()
+}
+
+// swap remplace la connexion réservée et libère la précédente.
+func (h *limiterHold) swap(release func()) {
+	h.mu.Lock()
+	old := h.release
+	h.release = release
+	h.mu.Unlock()
+	if old != nil {
+		old()
+	}
+}
+
+// releaseOnClose libère la connexion réservée quand le corps de la réponse est fermé.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close ferme le corps puis libère la connexion.
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
@@ -0,0 +1,130 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacing(t *testing.T) {
+	l := newRateLimiter(20, 1, 0)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		release, err := l.acquire(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		release()
+	}
+	// Le premier jeton est immédiat, les 4 suivants arrivent toutes les 50ms
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Fatalf("expected requests to be spaced out, took only %v", elapsed)
+	}
+
+	// Un autre hôte dispose de son propre seau
+	start = time.Now()
+	release, err := l.acquire(context.Background(), "other.com")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	release()
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Fatalf("expected independent bucket per host, waited %v", elapsed)
+	}
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := newRateLimiter(1, 3, 0)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := l.acquire(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected burst of 3 to be immediate, took %v", elapsed)
+	}
+}
+
+func TestFetchMaxConnsPerHost(t *testing.T) {
+	var current, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		w.Write([]byte(`<html><body><h1>Hello</h1></body></html>`))
+	}))
+	defer srv.Close()
+
+	f := NewWithOptions(Options{Timeout: 5 * time.Second, IgnoreRobots: true, MaxConnsPerHost: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.Fetch(srv.URL); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak := atomic.LoadInt32(&peak); peak > 2 {
+		t.Fatalf("expected at most 2 concurrent connections, observed %d", peak)
+	}
+}
+
+func TestLimiterKey(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://Example.com/":      "example.com",
+		"https://example.com:443/a": "example.com",
+		"http://example.com:80/":    "example.com",
+		"http://example.com:443/":   "example.com:443",
+		"https://[::1]:8443/":       "::1:8443",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, raw, nil)
+		if got := limiterKey(req); got != want {
+			t.Errorf("%s: expected key %q, got %q", raw, want, got)
+		}
+	}
+}
+
+func TestRateLimitAppliesToRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1>Target</h1></body></html>`))
+	}))
+	defer target.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	f := NewWithOptions(Options{Timeout: 5 * time.Second, IgnoreRobots: true, RequestsPerSecond: 5, MaxConnsPerHost: 1})
+	if _, err := f.Fetch(origin.URL); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	// La redirection a consommé le jeton de l'hôte de destination
+	start := time.Now()
+	if _, err := f.Fetch(target.URL); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("expected the redirect to count against the target host, waited only %v", elapsed)
+	}
+}
@@ -0,0 +1,77 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/neturl"
+	"webextractor/internal/parser"
+	"webextractor/internal/types"
+)
+
+// ErrTooManyRefreshes est retournée quand une page redirige par meta refresh au-delà de la limite.
+var ErrTooManyRefreshes = errors.New("too many meta refreshes")
+
+// MetaRefresh suit les redirections <meta http-equiv="refresh"> des pages intermédiaires
+// (« vous allez être redirigé... ») sans attendre le délai annoncé. Les pages suivantes
+// sont récupérées par un simple GET ; un rafraîchissement vers la page elle-même arrête le suivi.
+type MetaRefresh struct {
+	next  Fetcher
+	limit int
+}
+
+// NewMetaRefresh retourne un MetaRefresh devant next qui suit au plus limit rafraîchissements.
+func NewMetaRefresh(next Fetcher, limit int) *MetaRefresh {
+	return &MetaRefresh{next: next, limit: limit}
+}
+
+// FetchDocument récupère le document puis suit ses meta refresh. Les métadonnées retournées
+// sont celles de la dernière page, avec l'URL demandée à l'origine.
+func (m *MetaRefresh) FetchDocument(ctx context.Context, req *types.FetchRequest) (*htmlparser.Node, *types.FetchMetadata, error) {
+	doc, meta, err := m.next.FetchDocument(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for hops := 0; ; hops++ {
+		target, ok := refreshURL(doc, meta.FinalURL)
+		if !ok {
+			break
+		}
+		if hops >= m.limit {
+			return nil, nil, fmt.Errorf("%w: stopped after %d (%s)", ErrTooManyRefreshes, m.limit, meta.FinalURL)
+		}
+
+		requested := meta.URL
+		doc, meta, err = m.next.FetchDocument(ctx, &types.FetchRequest{Method: http.MethodGet, URL: target})
+		if err != nil {
+			return nil, nil, fmt.Errorf("meta refresh to %s: %w", target, err)
+		}
+		meta.URL = requested
+	}
+	return doc, meta, nil
+}
+
+// refreshURL retourne la cible absolue du meta refresh de la page, si elle mène ailleurs.
+func refreshURL(doc *htmlparser.Node, pageURL string) (string, bool) {
+	raw, ok := parser.MetaRefresh(doc)
+	if !ok {
+		return "", false
+	}
+	page, err := neturl.Parse(pageURL)
+	if err != nil {
+		return "", false
+	}
+	target, err := parser.BaseURL(doc, page).Resolve(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return "", false
+	}
+	target.Fragment = ""
+	if target.String() == page.String() {
+		return "", false
+	}
+	return target.String(), true
+}
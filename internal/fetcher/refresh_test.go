@@ -0,0 +1,54 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"webextractor/internal/parser"
+)
+
+func TestMetaRefresh(t *testing.T) {
+	pages := map[string]string{
+		"/start":  `<html><head><meta http-equiv="Refresh" content="3; URL='/middle'"></head><body>Redirection...</body></html>`,
+		"/middle": `<html><head><base href="/docs/"><meta http-equiv="refresh" content="0;url=final"></head></html>`,
+		"/docs/final": `<html><head><meta http-equiv="refresh" content="30"><link rel="canonical" href="https://example.com/final">` +
+			`</head><body><h1>Final</h1></body></html>`,
+		"/self": `<html><head><meta http-equiv="refresh" content="5; url=/self#top"></head><body><h1>Self</h1></body></html>`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(pages[r.URL.Path]))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	f := NewMetaRefresh(NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true}), 2)
+
+	doc, meta, err := Get(ctx, f, srv.URL+"/start")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h1 := parser.FindAll(doc, "h1"); len(h1) != 1 || parser.TextContent(h1[0]) != "Final" {
+		t.Fatalf("expected the final page after meta refreshes")
+	}
+	if meta.URL != srv.URL+"/start" || meta.FinalURL != srv.URL+"/docs/final" {
+		t.Errorf("unexpected URLs: requested %s, final %s", meta.URL, meta.FinalURL)
+	}
+	if meta.Canonical != "https://example.com/final" {
+		t.Errorf("expected canonical URL, got %q", meta.Canonical)
+	}
+
+	// Un rafraîchissement vers la page elle-même n'est pas suivi
+	if _, meta, err := Get(ctx, f, srv.URL+"/self"); err != nil || meta.FinalURL != srv.URL+"/self" {
+		t.Fatalf("expected self refresh to stop, got %v (%v)", meta, err)
+	}
+
+	limited := NewMetaRefresh(NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true}), 1)
+	if _, _, err := Get(ctx, limited, srv.URL+"/start"); !errors.Is(err, ErrTooManyRefreshes) {
+		t.Fatalf("expected ErrTooManyRefreshes, got %v", err)
+	}
+}
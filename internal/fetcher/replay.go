@@ -0,0 +1,15 @@
+package fetcher
+
+import (
+	"webextractor/internal/har"
+)
+
+// NewReplay retourne un HTTPFetcher qui sert les réponses enregistrées dans un fichier HAR,
+// sans accès réseau ; une URL absente du fichier échoue avec har.ErrNotRecorded.
+// robots.txt n'est pas consulté : aucun serveur n'est sollicité.
+func NewReplay(harPath string) *HTTPFetcher {
+	return NewWithOptions(Options{
+		IgnoreRobots: true,
+		Doer:         func(Doer) Doer { return har.NewReplayer(harPath) },
+	})
+}
@@ -0,0 +1,141 @@
+package fetcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"webextractor/internal/neturl"
+	"webextractor/internal/types"
+)
+
+// Content-Types des corps de requête supportés
+const (
+	ContentTypeForm = "application/x-www-form-urlencoded"
+	ContentTypeJSON = "application/json"
+)
+
+// BuildRequest construit la requête initiale décrite par la configuration.
+// Les champs sont ajoutés à l'URL pour un GET, encodés dans le corps sinon.
+func BuildRequest(url string, rc types.RequestConfig) (*types.FetchRequest, error) {
+	req := &types.FetchRequest{URL: url, UserAgent: types.DefaultUserAgent, Method: strings.ToUpper(rc.Method)}
+
+	hasBody := rc.JSON != "" || rc.Multipart || len(rc.Files) > 0 || (len(rc.Fields) > 0 && req.Method != http.MethodGet)
+	if req.Method == "" {
+		req.Method = http.MethodGet
+		if hasBody {
+			req.Method = http.MethodPost
+		}
+	}
+
+	switch {
+	case rc.JSON != "":
+		if len(rc.Fields) > 0 || len(rc.Files) > 0 {
+			return nil, errors.New("a JSON body cannot be combined with form fields")
+		}
+		if !json.Valid([]byte(rc.JSON)) {
+			return nil, errors.New("invalid JSON body")
+		}
+		req.Body = []byte(rc.JSON)
+		req.ContentType = ContentTypeJSON
+
+	case rc.Multipart || len(rc.Files) > 0:
+		body, contentType, err := EncodeMultipart(rc.Fields, rc.Files)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+		req.ContentType = contentType
+
+	case len(rc.Fields) > 0 && req.Method == http.MethodGet:
+		u, err := neturl.Parse(url)
+		if err != nil {
+			return nil, err
+		}
+		u.RawQuery = joinQuery(u.RawQuery, rc.Fields.Encode())
+		req.URL = u.String()
+
+	case len(rc.Fields) > 0:
+		req.Body = []byte(rc.Fields.Encode())
+		req.ContentType = ContentTypeForm
+	}
+
+	return req, nil
+}
+
+// EncodeMultipart encode des champs et des fichiers au format multipart/form-data.
+// Les fichiers sont lus depuis le disque ; un chemin vide envoie une partie vide,
+// comme un navigateur pour un champ fichier non rempli.
+func EncodeMultipart(fields neturl.Values, files map[string]string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	for _, name := range sortedKeys(fields) {
+		for _, value := range fields[name] {
+			if err := w.WriteField(name, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := files[name]
+		if path == "" {
+			if _, err := w.CreateFormFile(name, ""); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+		part, err := w.CreateFormFile(name, filepath.Base(path))
+		if err != nil {
+			return nil, "", err
+		}
+		content, err := os.ReadFile(path) // #nosec G304 - fichier explicitement choisi par l'utilisateur
+		if err != nil {
+			return nil, "", fmt.Errorf("form file %s: %w", name, err)
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// joinQuery ajoute des paramètres encodés à une requête existante.
+func joinQuery(query, extra string) string {
+	switch {
+	case query == "":
+		return extra
+	case extra == "":
+		return query
+	default:
+		return query + "&" + extra
+	}
+}
+
+// sortedKeys retourne les clés triées pour un encodage déterministe.
+func sortedKeys(values neturl.Values) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,153 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"webextractor/internal/neturl"
+	"webextractor/internal/types"
+)
+
+// echoServer renvoie la méthode, le Content-Type et le corps reçus dans une page HTML.
+func echoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body><p id=\"method\">" + r.Method + "</p><p id=\"query\">" + r.URL.RawQuery +
+			"</p><p id=\"type\">" + r.Header.Get("Content-Type") + "</p><p id=\"body\">" + string(body) + "</p></body></html>"))
+	}))
+}
+
+func TestBuildRequest(t *testing.T) {
+	fields := neturl.Values{"q": {"red shoes"}, "page": {"2"}}
+
+	req, err := BuildRequest("https://example.com/search?lang=fr", types.RequestConfig{Method: "GET", Fields: fields})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Method != "GET" || req.URL != "https://example.com/search?lang=fr&page=2&q=red+shoes" || req.Body != nil {
+		t.Errorf("unexpected GET request: %+v", req)
+	}
+
+	req, err = BuildRequest("https://example.com/search", types.RequestConfig{Fields: fields})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Method != "POST" || req.ContentType != ContentTypeForm || string(req.Body) != "page=2&q=red+shoes" {
+		t.Errorf("unexpected form request: %+v", req)
+	}
+
+	req, err = BuildRequest("https://example.com/api", types.RequestConfig{Method: "put", JSON: `{"a":1}`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Method != "PUT" || req.ContentType != ContentTypeJSON || string(req.Body) != `{"a":1}` {
+		t.Errorf("unexpected JSON request: %+v", req)
+	}
+
+	if _, err := BuildRequest("https://example.com/api", types.RequestConfig{JSON: `{bad`}); err == nil {
+		t.Errorf("expected error for invalid JSON")
+	}
+}
+
+func TestSendMultipart(t *testing.T) {
+	var gotName, gotFile string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		gotName = r.FormValue("name")
+		if file, _, err := r.FormFile("doc"); err == nil {
+			content, _ := io.ReadAll(file)
+			gotFile = string(content)
+		}
+		w.Write([]byte(okPage))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "doc.txt")
+	if err := os.WriteFile(path, []byte("file content"), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	req, err := BuildRequest(srv.URL, types.RequestConfig{
+		Fields: neturl.Values{"name": {"report"}},
+		Files:  map[string]string{"doc": path},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true})
+	if _, _, err := f.FetchDocument(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotName != "report" || gotFile != "file content" {
+		t.Fatalf("unexpected multipart content: name=%q file=%q", gotName, gotFile)
+	}
+}
+
+func TestEncodeMultipartEmptyFile(t *testing.T) {
+	body, _, err := EncodeMultipart(nil, map[string]string{"doc": ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(body), `name="doc"; filename=""`) {
+		t.Errorf("expected an empty part without a file name, got %s", body)
+	}
+}
+
+func TestSubmitForm(t *testing.T) {
+	echo := echoServer(t)
+	defer echo.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>
+			<form id="search" action="results"><input name="q" value="default"><input type="hidden" name="src" value="home"></form>
+			<div class="box"><form method="POST" action="` + echo.URL + `/submit">
+				<input type="hidden" name="token" value="t1"><input name="qty" value="1">
+				<button id="send">Send</button>
+			</form></div>
+		</body></html>`))
+	})
+	mux.HandleFunc("/results", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><p id="q">` + r.URL.Query().Get("q") + `</p><p id="src">` + r.URL.Query().Get("src") + `</p></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true})
+
+	doc, _, err := SubmitForm(context.Background(), f, srv.URL+"/page", "#search", neturl.Values{"q": {"boots"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := renderText(doc); !strings.Contains(text, "boots") || !strings.Contains(text, "home") {
+		t.Fatalf("expected GET form with override and hidden input, got %q", text)
+	}
+
+	// Le sélecteur peut viser un élément à l'intérieur du formulaire
+	doc, _, err = SubmitForm(context.Background(), f, srv.URL+"/page", "#send", neturl.Values{"qty": {"3"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := renderText(doc)
+	if !strings.Contains(text, "POST") || !strings.Contains(text, "qty=3&token=t1") || !strings.Contains(text, ContentTypeForm) {
+		t.Fatalf("unexpected POST submission: %q", text)
+	}
+
+	if _, _, err := SubmitForm(context.Background(), f, srv.URL+"/page", "#missing", nil, nil); !errors.Is(err, ErrFormNotFound) {
+		t.Fatalf("expected ErrFormNotFound, got %v", err)
+	}
+}
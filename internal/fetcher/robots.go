@@ -0,0 +1,163 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"webextractor/internal/neturl"
+	"webextractor/internal/robots"
+)
+
+// ErrDisallowedByRobots est retournée quand robots.txt interdit l'accès à une URL.
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// maxRobotsSize limite la taille lue d'un robots.txt (RFC 9309 impose au moins 500 Kio).
+const maxRobotsSize = 500 * 1024
+
+// robotsEntry contient les règles d'un hôte et la date du dernier accès.
+type robotsEntry struct {
+	mu         sync.Mutex
+	rules      *robots.Rules
+	lastAccess time.Time
+}
+
+// robotsCache garde en mémoire les règles robots.txt de chaque hôte.
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+// newRobotsCache crée un cache vide.
+func newRobotsCache() *robotsCache {
+	return &robotsCache{entries: make(map[string]*robotsEntry)}
+}
+
+// checkRobots vérifie que l'URL est autorisée et respecte le Crawl-delay de l'hôte.
+func (f *HTTPFetcher) checkRobots(ctx context.Context, rawurl string) error {
+	u, err := neturl.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+
+	entry, err := f.robotsFor(ctx, u)
+	if err != nil {
+		return err
+	}
+
+	path := u.Path
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !entry.rules.Allowed(f.userAgent.String(), path) {
+		return fmt.Errorf("%w: %s", ErrDisallowedByRobots, rawurl)
+	}
+
+	// On attend la fin du Crawl-delay depuis la requête précédente
+	delay := entry.rules.CrawlDelay(f.userAgent.String())
+	if delay > 0 {
+		entry.mu.Lock()
+		defer entry.mu.Unlock()
+		if wait := time.Until(entry.lastAccess.Add(delay)); wait > 0 {
+			if err := sleepContext(ctx, wait); err != nil {
+				return err
+			}
+		}
+		entry.lastAccess = time.Now()
+	}
+
+	return nil
+}
+
+// robotsRequestKey marque le contexte du téléchargement d'un robots.txt, dont les
+// redirections ne sont pas soumises à robots.txt.
+type robotsRequestKey struct{}
+
+// checkRedirect vérifie chaque redirection suivie par le client : destination autorisée
+// en mode sûr, URL permise par le robots.txt de son hôte, puis limites de débit et de
+// connexions de cet hôte.
+func (f *HTTPFetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	// L'étape précédente est terminée : sa connexion est libérée avant de consulter
+	// robots.txt, qui peut être sur le même hôte
+	hold, _ := req.Context().Value(limiterHoldKey{}).(*limiterHold)
+	if hold != nil {
+		hold.swap(nil)
+	}
+	if f.safe != nil {
+		if err := f.safe.checkRedirect(req, via); err != nil {
+			return err
+		}
+	}
+	if !f.ignoreRobots && req.Context().Value(robotsRequestKey{}) == nil {
+		if err := f.checkRobots(req.Context(), req.URL.String()); err != nil {
+			return err
+		}
+	}
+	if hold == nil {
+		return nil
+	}
+	release, err := f.limiter.acquire(req.Context(), limiterKey(req))
+	if err != nil {
+		return err
+	}
+	hold.swap(release)
+	return nil
+}
+
+// robotsFor retourne les règles de l'hôte, en les téléchargeant au premier accès.
+func (f *HTTPFetcher) robotsFor(ctx context.Context, u *neturl.URL) (*robotsEntry, error) {
+	key := u.Scheme + "://" + u.Host
+
+	f.robots.mu.Lock()
+	entry, ok := f.robots.entries[key]
+	if !ok {
+		entry = &robotsEntry{}
+		f.robots.entries[key] = entry
+	}
+	f.robots.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.rules != nil {
+		return entry, nil
+	}
+
+	rules, err := f.downloadRobots(ctx, key+"/robots.txt")
+	if err != nil {
+		return nil, fmt.Errorf("robots.txt: %w", err)
+	}
+	entry.rules = rules
+	return entry, nil
+}
+
+// downloadRobots récupère et analyse un robots.txt selon les règles de la RFC 9309 :
+// une erreur 4xx autorise tout, une erreur 5xx interdit tout.
+func (f *HTTPFetcher) downloadRobots(ctx context.Context, robotsURL string) (*robots.Rules, error) {
+	ctx = context.WithValue(ctx, robotsRequestKey{}, true)
+	req, err := f.newRequest(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return robots.DisallowAll(), nil
+	case resp.StatusCode != http.StatusOK:
+		return robots.AllowAll(), nil
+	}
+
+	return robots.Parse(io.LimitReader(resp.Body, maxRobotsSize))
+}
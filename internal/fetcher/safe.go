@@ -0,0 +1,205 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"webextractor/internal/strconv"
+	"webextractor/internal/types"
+)
+
+// ErrUnsafeDestination est la cause de toutes les UnsafeDestinationError.
+var ErrUnsafeDestination = errors.New("unsafe destination")
+
+// UnsafeDestinationError est retournée en mode sûr quand une requête, une redirection
+// ou une connexion vise une destination interdite.
+type UnsafeDestinationError struct {
+	URL    string // URL ou adresse refusée
+	Reason string // "loopback address 127.0.0.1", "port 22 not allowed"...
+}
+
+// Error implémente l'interface error.
+func (e *UnsafeDestinationError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", ErrUnsafeDestination, e.URL, e.Reason)
+}
+
+// Unwrap permet errors.Is(err, ErrUnsafeDestination).
+func (e *UnsafeDestinationError) Unwrap() error {
+	return ErrUnsafeDestination
+}
+
+// blockedPrefixes complète les catégories de netip avec les plages réservées
+// et celles des services de métadonnées des hébergeurs.
+var blockedPrefixes = []struct {
+	prefix netip.Prefix
+	reason string
+}{
+	{netip.MustParsePrefix("0.0.0.0/8"), "\"this\" network"},
+	{netip.MustParsePrefix("100.64.0.0/10"), "shared address space"}, // dont 100.100.100.200 (métadonnées Alibaba)
+	{netip.MustParsePrefix("192.0.0.0/24"), "IETF protocol assignment"},
+	{netip.MustParsePrefix("198.18.0.0/15"), "benchmarking network"},
+	{netip.MustParsePrefix("240.0.0.0/4"), "reserved network"},
+	{netip.MustParsePrefix("64:ff9b::/96"), "NAT64 address"},
+}
+
+// guard applique la politique du mode sûr. Les adresses sont vérifiées au moment de la
+// connexion, après résolution DNS, puis c'est l'adresse vérifiée qui est contactée :
+// ni une redirection ni un changement de réponse DNS ne permettent de la contourner.
+type guard struct {
+	ports   map[int]bool    // vide = tous les ports
+	schemes map[string]bool // http et https par défaut
+	hosts   []string        // hôtes autorisés, "*.domaine" pour les sous-domaines
+	nets    []netip.Prefix  // plages autorisées
+	dialer  *net.Dialer
+	lookup  func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// newGuard construit la politique du mode sûr. Une entrée d'allowlist invalide est une
+// erreur de configuration.
+func newGuard(safe types.SafeMode) (*guard, error) {
+	g := &guard{
+		ports:   map[int]bool{},
+		schemes: map[string]bool{},
+		dialer:  &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		lookup:  net.DefaultResolver.LookupIPAddr,
+	}
+	for _, port := range safe.Ports {
+		g.ports[port] = true
+	}
+	schemes := safe.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	for _, scheme := range schemes {
+		g.schemes[strings.ToLower(scheme)] = true
+	}
+	for _, entry := range safe.Allow {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			g.nets = append(g.nets, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			g.nets = append(g.nets, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else if entry != "" && !strings.ContainsAny(entry, "/:") {
+			g.hosts = append(g.hosts, strings.ToLower(entry))
+		} else {
+			return nil, fmt.Errorf("invalid allowlist entry %q (expected a host, an IP or a CIDR range)", entry)
+		}
+	}
+	return g, nil
+}
+
+// checkURL vérifie le schéma et le port d'une requête ou d'une redirection.
+func (g *guard) checkURL(req *http.Request) error {
+	u := req.URL
+	if !g.schemes[strings.ToLower(u.Scheme)] {
+		return &UnsafeDestinationError{URL: u.String(), Reason: fmt.Sprintf("scheme %q not allowed", u.Scheme)}
+	}
+	if len(g.ports) > 0 {
+		port := u.Port()
+		if port == "" {
+			port = "80"
+			if strings.EqualFold(u.Scheme, "https") {
+				port = "443"
+			}
+		}
+		if n, err := strconv.Atoi(port); err != nil || !g.ports[n] {
+			return &UnsafeDestinationError{URL: u.String(), Reason: "port " + port + " not allowed"}
+		}
+	}
+	return nil
+}
+
+// checkRedirect applique la politique à chaque redirection, avec la limite habituelle du client.
+func (g *guard) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return g.checkURL(req)
+}
+
+// dialContext résout l'hôte, refuse les adresses internes puis se connecte à la première
+// adresse autorisée. Les hôtes de l'allowlist sont contactés sans vérification.
+func (g *guard) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if g.allowedHost(host) {
+		return g.dialer.DialContext(ctx, network, address)
+	}
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		ips, err := g.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if addr, ok := netip.AddrFromSlice(ip.IP); ok {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+
+	var refused error
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		if reason := g.blocked(addr); reason != "" {
+			refused = &UnsafeDestinationError{URL: address, Reason: reason + " " + addr.String()}
+			continue
+		}
+		return g.dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+	}
+	if refused == nil {
+		refused = fmt.Errorf("no address found for %s", host)
+	}
+	return nil, refused
+}
+
+// allowedHost retourne true si l'hôte figure dans l'allowlist.
+func (g *guard) allowedHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range g.hosts {
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// blocked retourne la raison du refus d'une adresse, ou "" si elle est autorisée.
+func (g *guard) blocked(addr netip.Addr) string {
+	for _, prefix := range g.nets {
+		if prefix.Contains(addr) {
+			return ""
+		}
+	}
+	switch {
+	case addr.IsLoopback():
+		return "loopback address"
+	case addr.IsUnspecified():
+		return "unspecified address"
+	case addr.IsLinkLocalUnicast():
+		return "link-local address" // dont 169.254.169.254 (métadonnées cloud)
+	case addr.IsPrivate():
+		return "private address" // dont fd00:ec2::254 (métadonnées AWS en IPv6)
+	case addr.IsMulticast():
+		return "multicast address"
+	case addr.Is4() && addr == netip.AddrFrom4([4]byte{255, 255, 255, 255}):
+		return "broadcast address"
+	}
+	for _, b := range blockedPrefixes {
+		if b.prefix.Contains(addr) {
+			return b.reason
+		}
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"webextractor/internal/types"
+)
+
+func TestSafeModeBlocksInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://"+strings.Replace(r.Host, "localhost", "127.0.0.1", 1)+"/", http.StatusFound)
+			return
+		}
+		w.Write([]byte(`<html><body><h1>Internal</h1></body></html>`))
+	}))
+	defer srv.Close()
+	port := srv.URL[strings.LastIndex(srv.URL, ":")+1:]
+	ctx := context.Background()
+
+	safe := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, Safe: types.SafeMode{Enabled: true}})
+	_, err := safe.FetchContext(ctx, srv.URL)
+	var unsafe *UnsafeDestinationError
+	if !errors.As(err, &unsafe) || !strings.Contains(unsafe.Reason, "loopback") {
+		t.Fatalf("expected loopback to be blocked, got %v", err)
+	}
+
+	// Un nom résolu vers une adresse interne est refusé à la connexion (DNS rebinding)
+	safe.safe.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		return []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}, nil
+	}
+	if _, err := safe.FetchContext(ctx, "http://rebind.example:"+port+"/"); !errors.Is(err, ErrUnsafeDestination) {
+		t.Fatalf("expected rebinding to be blocked, got %v", err)
+	}
+
+	// L'allowlist autorise l'hôte, mais pas la redirection vers une adresse interne
+	allowed := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, Safe: types.SafeMode{Enabled: true, Allow: []string{"localhost"}}})
+	if _, err := allowed.FetchContext(ctx, "http://localhost:"+port+"/"); err != nil {
+		t.Fatalf("expected allowlisted host to be fetched, got %v", err)
+	}
+	if _, err := allowed.FetchContext(ctx, "http://localhost:"+port+"/redirect"); !errors.Is(err, ErrUnsafeDestination) {
+		t.Fatalf("expected redirect to be blocked, got %v", err)
+	}
+
+	cidr := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, Safe: types.SafeMode{Enabled: true, Allow: []string{"127.0.0.0/8"}}})
+	if _, err := cidr.FetchContext(ctx, srv.URL); err != nil {
+		t.Fatalf("expected allowlisted range to be fetched, got %v", err)
+	}
+}
+
+func TestSafeModePortsSchemesAndLocalFiles(t *testing.T) {
+	ctx := context.Background()
+	f := NewWithOptions(Options{Timeout: time.Second, IgnoreRobots: true, Safe: types.SafeMode{
+		Enabled: true,
+		Ports:   []int{443},
+		Schemes: []string{"https"},
+	}})
+
+	for _, url := range []string{"http://example.com/", "https://example.com:8443/", filepath.Join(t.TempDir(), "page.html")} {
+		if _, err := f.FetchContext(ctx, url); !errors.Is(err, ErrUnsafeDestination) {
+			t.Errorf("%s: expected ErrUnsafeDestination, got %v", url, err)
+		}
+	}
+
+	invalid := NewWithOptions(Options{Safe: types.SafeMode{Enabled: true, Allow: []string{"10.0.0.0/99"}}})
+	if _, err := invalid.FetchContext(ctx, "https://example.com/"); err == nil || !strings.Contains(err.Error(), "allowlist") {
+		t.Fatalf("expected invalid allowlist error, got %v", err)
+	}
+}
+
+func TestGuardBlockedRanges(t *testing.T) {
+	g, err := newGuard(types.SafeMode{Enabled: true, Allow: []string{"10.1.0.0/16"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := map[string]bool{
+		"127.0.0.1":        true,
+		"::1":              true,
+		"169.254.169.254":  true,
+		"10.0.0.1":         true,
+		"192.168.1.1":      true,
+		"fd00:ec2::254":    true,
+		"100.100.100.200":  true,
+		"0.0.0.0":          true,
+		"::ffff:127.0.0.1": true,
+		"10.1.2.3":         false, // allowlist
+		"93.184.216.34":    false,
+		"2606:4700::1111":  false,
+	}
+	for ip, want := range tests {
+		if got := g.blocked(netip.MustParseAddr(ip).Unmap()) != ""; got != want {
+			t.Errorf("blocked(%s) = %v, want %v", ip, got, want)
+		}
+	}
+}
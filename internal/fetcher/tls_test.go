@@ -0,0 +1,170 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"webextractor/internal/types"
+)
+
+// writePEM écrit un bloc PEM dans un fichier temporaire et retourne son chemin.
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+// newClientCertificate génère un certificat client auto-signé et retourne
+// le certificat analysé ainsi que les chemins du certificat et de la clé.
+func newClientCertificate(t *testing.T) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "webextractor-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return cert, certPath, keyPath
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(okPage))
+	})
+}
+
+func TestTLSCustomCA(t *testing.T) {
+	srv := httptest.NewTLSServer(okHandler())
+	defer srv.Close()
+
+	// Sans le bundle de CA, le certificat de test est refusé
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true})
+	if _, err := f.FetchContext(context.Background(), srv.URL); err == nil {
+		t.Fatalf("expected certificate verification error")
+	}
+
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+	f = NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, TLS: types.TLSConfig{CAFile: caFile}})
+	_, meta, err := f.FetchWithMetadata(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta.TLS == nil || meta.TLS.Version != "TLS 1.3" {
+		t.Fatalf("expected TLS 1.3 metadata, got %+v", meta.TLS)
+	}
+	if len(meta.TLS.PeerCertificates) == 0 || meta.TLS.PeerCertificates[0].SHA256 == "" {
+		t.Fatalf("expected peer certificate summary, got %+v", meta.TLS.PeerCertificates)
+	}
+}
+
+func TestTLSInsecureSkipVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(okHandler())
+	defer srv.Close()
+
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, TLS: types.TLSConfig{InsecureSkipVerify: true}})
+	if _, err := f.FetchContext(context.Background(), srv.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	srv := httptest.NewUnstartedServer(okHandler())
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, TLS: types.TLSConfig{CAFile: caFile}})
+	_, meta, err := f.FetchWithMetadata(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta.TLS.Version != "TLS 1.2" {
+		t.Fatalf("expected TLS 1.2, got %s", meta.TLS.Version)
+	}
+
+	f = NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, TLS: types.TLSConfig{CAFile: caFile, MinVersion: "1.3"}})
+	if _, err := f.FetchContext(context.Background(), srv.URL); err == nil {
+		t.Fatalf("expected handshake failure below the minimum version")
+	}
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+	clientCert, certFile, keyFile := newClientCertificate(t)
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+
+	srv := httptest.NewUnstartedServer(okHandler())
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+
+	f := NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, TLS: types.TLSConfig{CAFile: caFile}})
+	if _, err := f.FetchContext(context.Background(), srv.URL); err == nil {
+		t.Fatalf("expected failure without a client certificate")
+	}
+
+	f = NewWithOptions(Options{Timeout: 2 * time.Second, IgnoreRobots: true, TLS: types.TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}})
+	if _, err := f.FetchContext(context.Background(), srv.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTLSInvalidConfig(t *testing.T) {
+	cases := []types.TLSConfig{
+		{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		{MinVersion: "2.0"},
+		{CertFile: "client.pem"},
+	}
+	for _, cfg := range cases {
+		f := NewWithOptions(Options{Timeout: time.Second, IgnoreRobots: true, TLS: cfg})
+		if _, err := f.FetchContext(context.Background(), "https://example.com/"); !errors.Is(err, ErrInvalidTLSConfig) {
+			t.Errorf("%+v: expected ErrInvalidTLSConfig, got %v", cfg, err)
+		}
+	}
+}
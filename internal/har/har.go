@@ -0,0 +1,144 @@
+// Package har enregistre les échanges HTTP au format HAR 1.2 et les rejoue hors ligne.
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// Version est la version du format HAR produite.
+const Version = "1.2"
+
+// redacted remplace la valeur des en-têtes contenant des secrets.
+const redacted = "REDACTED"
+
+// doer est satisfaite par *http.Client et par les Doer du fetcher.
+type doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// File est la racine d'un fichier HAR.
+type File struct {
+	Log Log `json:"log"`
+}
+
+// Log contient les échanges enregistrés.
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator identifie l'outil qui a produit le fichier.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry représente un échange requête/réponse.
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"` // RFC 3339 avec millisecondes
+	Time            float64  `json:"time"`            // durée totale en millisecondes
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+}
+
+// Request décrit la requête envoyée.
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Response décrit la réponse reçue.
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"` // URL finale quand le client a suivi des redirections
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// NameValue est une paire nom/valeur (en-tête, cookie, paramètre).
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData contient le corps de la requête.
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Content contient le corps de la réponse, encodé en base64 s'il n'est pas du texte UTF-8.
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Timings détaille la durée d'un échange en millisecondes.
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Read analyse un fichier HAR.
+func Read(r io.Reader) (*File, error) {
+	var file File
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid HAR: %w", err)
+	}
+	return &file, nil
+}
+
+// ReadFile analyse le fichier HAR situé au chemin donné.
+func ReadFile(path string) (*File, error) {
+	f, err := os.Open(path) // #nosec G304 - fichier explicitement choisi par l'utilisateur
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// headerList convertit des en-têtes HTTP en liste HAR, triée et sans secrets.
+func headerList(h http.Header) []NameValue {
+	list := []NameValue{}
+	for _, name := range sortedHeaderNames(h) {
+		for _, value := range h[name] {
+			if isSensitiveHeader(name) {
+				value = redacted
+			}
+			list = append(list, NameValue{Name: name, Value: value})
+		}
+	}
+	return list
+}
+
+// isSensitiveHeader retourne true pour les en-têtes qui transportent des identifiants.
+func isSensitiveHeader(name string) bool {
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package har_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"webextractor/internal/fetcher"
+	"webextractor/internal/har"
+	"webextractor/internal/parser"
+	"webextractor/internal/types"
+)
+
+func newServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`<html><body><h1>Recorded</h1></body></html>`))
+	})
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><p>` + string(body) + `</p></body></html>`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := newServer()
+	path := filepath.Join(t.TempDir(), "run.har")
+
+	var recorder *har.Recorder
+	live := fetcher.NewWithOptions(fetcher.Options{
+		Timeout:      2 * time.Second,
+		IgnoreRobots: true,
+		Credentials:  types.Credentials{BearerToken: "top-secret"},
+		Doer: func(client fetcher.Doer) fetcher.Doer {
+			recorder = har.NewRecorder(client, har.Creator{Name: "test", Version: "1"})
+			return recorder
+		},
+	})
+
+	ctx := context.Background()
+	if _, err := live.FetchContext(ctx, srv.URL+"/old"); err != nil {
+		t.Fatalf("live fetch: %v", err)
+	}
+	req, _ := fetcher.BuildRequest(srv.URL+"/search", types.RequestConfig{JSON: `{"q":"go"}`})
+	if _, _, err := live.FetchDocument(ctx, req); err != nil {
+		t.Fatalf("live send: %v", err)
+	}
+	if err := recorder.WriteFile(path); err != nil {
+		t.Fatalf("write HAR: %v", err)
+	}
+	srv.Close()
+
+	file, err := har.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read HAR: %v", err)
+	}
+	if len(file.Log.Entries) != 2 || file.Log.Version != har.Version {
+		t.Fatalf("expected 2 entries, got %d", len(file.Log.Entries))
+	}
+	first := file.Log.Entries[0]
+	if first.Response.RedirectURL != srv.URL+"/page" {
+		t.Errorf("expected redirect URL, got %q", first.Response.RedirectURL)
+	}
+	for _, h := range append(first.Request.Headers, first.Response.Headers...) {
+		if (h.Name == "Authorization" || h.Name == "Set-Cookie") && h.Value != "REDACTED" {
+			t.Errorf("secret header %s not redacted: %q", h.Name, h.Value)
+		}
+	}
+	if post := file.Log.Entries[1].Request.PostData; post == nil || post.Text != `{"q":"go"}` {
+		t.Errorf("expected recorded JSON body, got %+v", post)
+	}
+
+	// Le serveur est arrêté : les réponses viennent uniquement du fichier
+	replay := fetcher.NewReplay(path)
+	doc, meta, err := replay.FetchWithMetadata(ctx, srv.URL+"/old")
+	if err != nil {
+		t.Fatalf("replay fetch: %v", err)
+	}
+	if h1 := parser.FindAll(doc, "h1"); len(h1) != 1 || parser.TextContent(h1[0]) != "Recorded" {
+		t.Errorf("unexpected replayed document")
+	}
+	if meta.FinalURL != srv.URL+"/page" {
+		t.Errorf("expected final URL after redirect, got %s", meta.FinalURL)
+	}
+
+	doc, _, err = replay.FetchDocument(ctx, req)
+	if err != nil {
+		t.Fatalf("replay send: %v", err)
+	}
+	if p := parser.FindAll(doc, "p"); len(p) != 1 || !strings.Contains(parser.TextContent(p[0]), `"q"`) {
+		t.Errorf("unexpected replayed POST response")
+	}
+
+	if _, err := replay.FetchContext(ctx, srv.URL+"/missing"); !errors.Is(err, har.ErrNotRecorded) {
+		t.Fatalf("expected ErrNotRecorded, got %v", err)
+	}
+}
+
+func TestReplayRepeatsInOrder(t *testing.T) {
+	file := &har.File{Log: har.Log{Version: har.Version, Entries: []har.Entry{
+		{Request: har.Request{Method: "GET", URL: "https://example.com/n"}, Response: har.Response{Status: 200, Content: har.Content{Text: "1"}}},
+		{Request: har.Request{Method: "GET", URL: "https://example.com/n"}, Response: har.Response{Status: 200, Content: har.Content{Text: "Mg==", Encoding: "base64"}}},
+	}}}
+	replayer := har.NewReplayerFromFile(file)
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/n", nil)
+		resp, err := replayer.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		got = append(got, string(body))
+	}
+	if strings.Join(got, ",") != "1,2,2" {
+		t.Fatalf("expected responses in order then the last repeated, got %v", got)
+	}
+}
+
+func TestReplayerMissingFile(t *testing.T) {
+	replayer := har.NewReplayer(filepath.Join(t.TempDir(), "missing.har"))
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	if _, err := replayer.Do(req); err == nil {
+		t.Fatalf("expected error for missing HAR file")
+	}
+
+	var buf bytes.Buffer
+	if _, err := har.NewRecorder(http.DefaultClient, har.Creator{}).WriteTo(&buf); err != nil || !strings.Contains(buf.String(), `"entries": []`) {
+		t.Fatalf("expected empty HAR log, got %q (%v)", buf.String(), err)
+	}
+}
@@ -0,0 +1,189 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Recorder enregistre chaque échange effectué par le Doer qu'il enveloppe.
+// Il est sûr pour un usage concurrent.
+type Recorder struct {
+	next    doer
+	creator Creator
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder retourne un Recorder qui transmet les requêtes à next.
+func NewRecorder(next doer, creator Creator) *Recorder {
+	return &Recorder{next: next, creator: creator}
+}
+
+// Do exécute la requête puis enregistre la requête et la réponse complètes.
+// Le corps de la réponse est lu entièrement puis rendu à l'appelant.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	reqBody, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	wait := time.Since(start)
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	total := time.Since(start)
+
+	entry := Entry{
+		StartedDateTime: start.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		Time:            milliseconds(total),
+		Request:         harRequest(req, reqBody),
+		Response:        harResponse(resp, body),
+		Timings:         Timings{Wait: milliseconds(wait), Receive: milliseconds(total - wait)},
+	}
+	// Le client suit les redirections : l'URL finale permet de les rejouer fidèlement
+	if resp.Request != nil && resp.Request.URL.String() != req.URL.String() {
+		entry.Response.RedirectURL = resp.Request.URL.String()
+	}
+
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Entries retourne une copie des échanges enregistrés.
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry{}, r.entries...)
+}
+
+// WriteTo écrit le fichier HAR indenté.
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(File{Log: Log{Version: Version, Creator: r.creator, Entries: r.Entries()}}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// WriteFile écrit le fichier HAR au chemin donné, via un fichier temporaire renommé.
+func (r *Recorder) WriteFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := r.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// requestBody lit le corps de la requête sans le consommer.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// harRequest convertit une requête HTTP en requête HAR.
+func harRequest(req *http.Request, body []byte) Request {
+	hr := Request{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []NameValue{},
+		Headers:     headerList(req.Header),
+		QueryString: []NameValue{},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	query := req.URL.Query()
+	for _, name := range sortedHeaderNames(query) {
+		for _, value := range query[name] {
+			hr.QueryString = append(hr.QueryString, NameValue{Name: name, Value: value})
+		}
+	}
+	if len(body) > 0 {
+		hr.PostData = &PostData{MimeType: req.Header.Get("Content-Type"), Text: string(body)}
+	}
+	return hr
+}
+
+// harResponse convertit une réponse HTTP en réponse HAR.
+func harResponse(resp *http.Response, body []byte) Response {
+	content := Content{Size: len(body), MimeType: resp.Header.Get("Content-Type")}
+	if utf8.Valid(body) {
+		content.Text = string(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	}
+
+	return Response{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []NameValue{},
+		Headers:     headerList(resp.Header),
+		Content:     content,
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+}
+
+// sortedHeaderNames retourne les clés triées pour un fichier déterministe.
+func sortedHeaderNames(m map[string][]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// milliseconds convertit une durée en millisecondes fractionnaires.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,140 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrNotRecorded est retournée en rejeu quand aucune réponse n'a été enregistrée pour la requête.
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Replayer sert les réponses d'un fichier HAR sans aucun accès réseau.
+// Les requêtes sont associées par méthode et URL ; quand une même requête a été
+// enregistrée plusieurs fois, les réponses sont servies dans l'ordre puis la
+// dernière est répétée. Il est sûr pour un usage concurrent.
+type Replayer struct {
+	path string
+	once sync.Once
+	err  error
+
+	mu      sync.Mutex
+	entries map[string][]Entry
+	served  map[string]int
+}
+
+// NewReplayer retourne un Replayer pour le fichier HAR donné. Le fichier est lu
+// à la première requête ; une erreur de lecture est retournée par chaque requête.
+func NewReplayer(path string) *Replayer {
+	return &Replayer{path: path}
+}
+
+// NewReplayerFromFile retourne un Replayer pour un fichier HAR déjà analysé.
+func NewReplayerFromFile(file *File) *Replayer {
+	r := &Replayer{}
+	r.once.Do(func() { r.index(file) })
+	return r
+}
+
+// Do retourne la réponse enregistrée pour la requête, ou ErrNotRecorded.
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	r.once.Do(func() {
+		file, err := ReadFile(r.path)
+		if err != nil {
+			r.err = fmt.Errorf("har replay: %w", err)
+			return
+		}
+		r.index(file)
+	})
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	key := entryKey(req.Method, req.URL.String())
+	r.mu.Lock()
+	candidates := r.entries[key]
+	n := r.served[key]
+	if n < len(candidates) {
+		r.served[key] = n + 1
+	}
+	r.mu.Unlock()
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("har replay: %w for %s %s", ErrNotRecorded, req.Method, req.URL)
+	}
+	if n >= len(candidates) {
+		n = len(candidates) - 1
+	}
+	return replayResponse(req, candidates[n])
+}
+
+// index regroupe les échanges par méthode et URL.
+func (r *Replayer) index(file *File) {
+	r.entries = map[string][]Entry{}
+	r.served = map[string]int{}
+	for _, entry := range file.Log.Entries {
+		key := entryKey(entry.Request.Method, entry.Request.URL)
+		r.entries[key] = append(r.entries[key], entry)
+	}
+}
+
+// replayResponse reconstruit une réponse HTTP à partir d'un échange enregistré.
+func replayResponse(req *http.Request, entry Entry) (*http.Response, error) {
+	body := []byte(entry.Response.Content.Text)
+	if entry.Response.Content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(entry.Response.Content.Text)
+		if err != nil {
+			return nil, fmt.Errorf("har replay: invalid base64 content for %s: %w", entry.Request.URL, err)
+		}
+		body = decoded
+	}
+
+	header := http.Header{}
+	for _, h := range entry.Response.Headers {
+		header.Add(h.Name, h.Value)
+	}
+
+	// Une réponse obtenue après redirection garde son URL finale
+	final := req
+	if entry.Response.RedirectURL != "" {
+		redirected, err := http.NewRequestWithContext(req.Context(), req.Method, entry.Response.RedirectURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("har replay: invalid redirect URL: %w", err)
+		}
+		final = redirected
+	}
+
+	proto := entry.Response.HTTPVersion
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	status := http.StatusText(entry.Response.Status)
+	if entry.Response.StatusText != "" {
+		status = entry.Response.StatusText
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.Status, status),
+		StatusCode:    entry.Response.Status,
+		Proto:         proto,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       final,
+	}, nil
+}
+
+// entryKey identifie une requête par sa méthode et son URL.
+func entryKey(method, url string) string {
+	return strings.ToUpper(method) + " " + url
+}
@@ -23,6 +23,19 @@ type Node struct {
 	Type NodeType    // On peut ajouter un type à un nœud exemple (TextNode, ElementNode, CommentNode, etc.)
 	Data string      // On peut ajouter du texte à un nœud exemple (Hello World)
 	Attr []Attribute // On peut ajouter des attributs à un nœud exemple (class, id, etc.)
+
+	Namespace string // URI de l'espace de noms de l'élément (documents XML uniquement)
+}
+
+// xmlDocument est le Data du nœud document produit par ParseXML.
+const xmlDocument = "xml"
+
+// InXMLDocument retourne true si le nœud appartient à un document analysé comme XML.
+func (n *Node) InXMLDocument() bool {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return n.Type == DocumentNode && n.Data == xmlDocument
 }
 
 // AppendChild ajoute un nœud enfant à la fin des enfants du nœud donné.
@@ -1,17 +1,58 @@
 package htmlparser
 
 import (
+	"context"
 	"io"
 	"strings"
 )
 
+// ctxCheckInterval est le nombre de tokens traités entre deux vérifications du contexte.
+const ctxCheckInterval = 256
+
 // Parse analyse le HTML depuis un reader et retourne le nœud racine.
 func Parse(r io.Reader) (*Node, error) {
-	tokenizer := NewTokenizer(r)
+	return ParseContext(context.Background(), r)
+}
+
+// ParseContext analyse le HTML comme Parse mais s'arrête dès que le contexte est annulé.
+func ParseContext(ctx context.Context, r io.Reader) (*Node, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parse(ctx, NewTokenizer(r), false)
+}
+
+// ParseXML analyse un document XML (RSS, sitemap, XHTML...) et retourne le nœud racine.
+// La casse des noms est conservée, aucune balise n'est considérée comme vide par défaut
+// et chaque élément reçoit l'URI de son espace de noms.
+func ParseXML(r io.Reader) (*Node, error) {
+	return ParseXMLContext(context.Background(), r)
+}
+
+// ParseXMLContext analyse le XML comme ParseXML mais s'arrête dès que le contexte est annulé.
+func ParseXMLContext(ctx context.Context, r io.Reader) (*Node, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parse(ctx, NewXMLTokenizer(r), true)
+}
+
+// parse construit l'arbre à partir des tokens, en mode HTML ou XML.
+func parse(ctx context.Context, tokenizer *Tokenizer, xml bool) (*Node, error) {
 	doc := &Node{Type: DocumentNode}
+	if xml {
+		doc.Data = xmlDocument
+	}
 	stack := []*Node{doc}
 
-	for {
+	for count := 1; ; count++ {
+		// On vérifie régulièrement que l'analyse n'a pas été annulée
+		if count%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
 		tokenType := tokenizer.Next()
 		if tokenType == ErrorToken {
 			break
@@ -39,8 +80,11 @@ func Parse(r io.Reader) (*Node, error) {
 				Attr: token.Attr,
 			}
 			current.AppendChild(element)
+			if xml {
+				element.Namespace = lookupNamespace(element, prefixOf(element.Data))
+			}
 			// Si la balise n'est pas auto-fermante, on l'ajoute à la pile
-			if !isSelfClosing(token.Data) {
+			if xml || !isSelfClosing(token.Data) {
 				// On ajoute le nœud à la pile
 				stack = append(stack, element)
 			}
@@ -52,6 +96,9 @@ func Parse(r io.Reader) (*Node, error) {
 				Attr: token.Attr,
 			}
 			current.AppendChild(element)
+			if xml {
+				element.Namespace = lookupNamespace(element, prefixOf(element.Data))
+			}
 
 		case EndTagToken:
 			if len(stack) > 1 {
@@ -91,3 +138,27 @@ func isSelfClosing(tag string) bool {
 	// On retourne true si la balise est auto-fermante
 	return selfClosing[strings.ToLower(tag)]
 }
+
+// prefixOf retourne le préfixe d'un nom qualifié ("dc:creator" → "dc").
+func prefixOf(name string) string {
+	if idx := strings.IndexByte(name, ':'); idx >= 0 {
+		return name[:idx]
+	}
+	return ""
+}
+
+// lookupNamespace cherche la déclaration xmlns du préfixe dans l'élément puis ses ancêtres.
+func lookupNamespace(n *Node, prefix string) string {
+	key := "xmlns"
+	if prefix != "" {
+		key += ":" + prefix
+	}
+	for cur := n; cur != nil; cur = cur.Parent {
+		for _, a := range cur.Attr {
+			if a.Key == key {
+				return a.Val
+			}
+		}
+	}
+	return ""
+}
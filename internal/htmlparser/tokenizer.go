@@ -1,6 +1,7 @@
 package htmlparser
 
 import (
+	"bytes"
 	"io"
 	"strings"
 	"unicode"
@@ -17,6 +18,7 @@ const (
 	SelfClosingTagToken
 	CommentToken
 	DoctypeToken
+	ProcessingInstructionToken
 )
 
 // Token représente un token trouvé pendant l'analyse.
@@ -31,6 +33,7 @@ type Tokenizer struct {
 	raw   []byte // le HTML stocké en mémoire (tout le contenu)
 	pos   int    // où on en est (curseur actuel dans raw)
 	token Token  // le  dernier token trouvé
+	xml   bool   // mode XML : casse conservée et sections CDATA
 }
 
 // NewTokenizer crée un nouveau tokenizer.
@@ -42,6 +45,14 @@ func NewTokenizer(r io.Reader) *Tokenizer {
 	}
 }
 
+// NewXMLTokenizer crée un tokenizer en mode XML : les noms de balises et d'attributs
+// gardent leur casse et les sections CDATA sont lues comme du texte.
+func NewXMLTokenizer(r io.Reader) *Tokenizer {
+	t := NewTokenizer(r)
+	t.xml = true
+	return t
+}
+
 // Next avance au token suivant.
 func (t *Tokenizer) Next() TokenType {
 	// Si on est à la fin du HTML, on retourne une erreur
@@ -105,11 +116,19 @@ func (t *Tokenizer) readTag() TokenType {
 	if t.pos < len(t.raw) && t.raw[t.pos] == '/' {
 		return t.readEndTag()
 	}
+	// Si le caractère courant est un ?, alors on lit une instruction de traitement (<?xml ...?>)
+	if t.pos < len(t.raw) && t.raw[t.pos] == '?' {
+		return t.readProcessingInstruction()
+	}
 
 	return t.readStartTag()
 }
 
 func (t *Tokenizer) readComment() TokenType {
+	// En mode XML, une section CDATA devient un texte brut
+	if t.xml && bytes.HasPrefix(t.raw[t.pos:], []byte("![CDATA[")) {
+		return t.readCDATA()
+	}
 	// Si le caractère courant est un ! et que le suivant est un - et que le suivant est un -, alors on lit une balise de commentaire
 	if t.pos+3 >= len(t.raw) || string(t.raw[t.pos:t.pos+3]) != "!--" {
 		// On gère le <!DOCTYPE> et les autres déclarations
@@ -154,6 +173,57 @@ func (t *Tokenizer) readComment() TokenType {
 	return ErrorToken
 }
 
+func (t *Tokenizer) readCDATA() TokenType {
+	// On ignore le "![CDATA["
+	t.pos += len("![CDATA[")
+	start := t.pos
+	end := bytes.Index(t.raw[start:], []byte("]]>"))
+	if end < 0 {
+		end = len(t.raw) - start
+		t.pos = len(t.raw)
+	} else {
+		t.pos = start + end + len("]]>")
+	}
+
+	// Le contenu d'une section CDATA est conservé tel quel, espaces compris
+	text := string(t.raw[start : start+end])
+	if text == "" {
+		return t.Next()
+	}
+	t.token = Token{
+		Type: TextToken,
+		Data: text,
+	}
+	return TextToken
+}
+
+func (t *Tokenizer) readProcessingInstruction() TokenType {
+	// On ignore le '?'
+	t.pos++
+	start := t.pos
+	// On parcourt jusqu'au "?>" de fermeture
+	end := bytes.Index(t.raw[start:], []byte("?>"))
+	if end < 0 {
+		t.skipToEnd()
+		end = t.pos - start
+	} else {
+		t.pos = start + end + len("?>")
+	}
+	t.token = Token{
+		Type: ProcessingInstructionToken,
+		Data: strings.TrimSpace(string(t.raw[start : start+end])),
+	}
+	return ProcessingInstructionToken
+}
+
+// name normalise un nom de balise ou d'attribut : minuscules en HTML, inchangé en XML.
+func (t *Tokenizer) name(b []byte) string {
+	if t.xml {
+		return string(b)
+	}
+	return strings.ToLower(string(b))
+}
+
 func (t *Tokenizer) readEndTag() TokenType {
 	// On ignore le '/'
 	t.pos++
@@ -165,7 +235,7 @@ func (t *Tokenizer) readEndTag() TokenType {
 	}
 
 	// On récupère le nom du tag
-	tagName := strings.ToLower(string(t.raw[start:t.pos]))
+	tagName := t.name(t.raw[start:t.pos])
 	// On ignore le reste de la balise de fermeture
 	t.skipToEnd()
 	// On crée un nouveau token de type EndTagToken avec le nom du tag trouvé
@@ -184,7 +254,7 @@ func (t *Tokenizer) readStartTag() TokenType {
 		t.pos++
 	}
 	// On récupère le nom du tag
-	tagName := strings.ToLower(string(t.raw[start:t.pos]))
+	tagName := t.name(t.raw[start:t.pos])
 	// On ignore les espaces
 	t.skipWhitespace()
 	// On parcourt le HTML jusqu'à trouver un > ou un /
@@ -230,7 +300,7 @@ func (t *Tokenizer) readAttribute() Attribute {
 		t.pos++
 	}
 
-	key := strings.ToLower(string(t.raw[start:t.pos]))
+	key := t.name(t.raw[start:t.pos])
 	if key == "" {
 		return Attribute{}
 	}
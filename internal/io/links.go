@@ -0,0 +1,38 @@
+package io
+
+import "context"
+
+// LinkReport est le rapport de vérification des liens découverts par un crawl.
+type LinkReport struct {
+	Checked int         `json:"checked"`
+	Broken  int         `json:"broken"`
+	Links   []LinkCheck `json:"links"` // liens cassés d'abord, puis dans l'ordre de découverte
+}
+
+// LinkCheck est le résultat de la vérification d'une URL.
+type LinkCheck struct {
+	URL          string     `json:"url"`
+	Status       int        `json:"status,omitempty"`
+	Method       string     `json:"method,omitempty"` // HEAD, ou GET si HEAD a échoué
+	Error        string     `json:"error,omitempty"`
+	Broken       bool       `json:"broken"`
+	External     bool       `json:"external"` // hors du site (domaine enregistrable) des pages qui le citent
+	FinalURL     string     `json:"final_url,omitempty"`
+	Redirects    []Redirect `json:"redirects,omitempty"`
+	ReferencedBy []string   `json:"referenced_by"` // pages contenant le lien
+}
+
+// Redirect est une réponse de redirection suivie pendant la vérification.
+type Redirect struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// WriteLinkReportContext écrit le rapport de liens ("-" signifie stdout), en abandonnant
+// l'écriture si le contexte est annulé.
+func WriteLinkReportContext(ctx context.Context, path string, report LinkReport) error {
+	if report.Links == nil {
+		report.Links = []LinkCheck{}
+	}
+	return writeJSON(ctx, path, report)
+}
@@ -1,23 +1,34 @@
 package io
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Result représente un résultat d'extraction.
 type Result struct {
 	Selector string   `json:"selector"`
 	Matches  []string `json:"matches"`
+	URLs     []string `json:"urls,omitempty"` // page de chaque correspondance (pagination)
 }
 
 // DocumentResult est la structure de niveau supérieur du format JSON.
 type DocumentResult struct {
-	URL     string   `json:"url"`
-	Results []Result `json:"results"`
+	URL        string            `json:"url"`
+	Canonical  string            `json:"canonical,omitempty"` // URL canonique déclarée par la page
+	Params     map[string]string `json:"params,omitempty"`    // valeurs du modèle d'URL ayant produit la page
+	Results    []Result          `json:"results"`
+	Pages      []string          `json:"pages,omitempty"`       // pages parcourues, dans l'ordre (pagination)
+	Depth      int               `json:"depth,omitempty"`       // distance à la page de départ (crawl)
+	Partial    bool              `json:"partial,omitempty"`     // parcours interrompu par un budget épuisé
+	StopReason string            `json:"stop_reason,omitempty"` // raison de l'interruption
+	Error      string            `json:"error,omitempty"`       // échec de la page dans un lot (-urls)
 }
 
 // StructuredResult représente le format de sortie structuré.
@@ -55,20 +66,99 @@ func validateOutputPath(path string) error {
 }
 
 // writeJSON écrit un objet JSON dans le chemin de fichier donné ("-" signifie stdout).
-func writeJSON(path string, data any) error {
+// Un fichier est d'abord écrit à côté de sa destination puis renommé : si le contexte est
+// annulé avant la fin, le fichier temporaire est supprimé et la destination reste intacte.
+// Un lien symbolique est suivi (c'est sa cible qui est remplacée) et les droits d'un
+// fichier existant sont conservés ; un nouveau fichier est créé comme par os.Create.
+func writeJSON(ctx context.Context, path string, data any) error {
 	if err := validateOutputPath(path); err != nil {
 		return fmt.Errorf("output path validation failed: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("output cancelled: %w", err)
+	}
 
-	enc := json.NewEncoder(os.Stdout)
-	if path != "-" && path != "" {
-		file, err := os.Create(path) // #nosec G304 - path validated above - on valide le chemin ci-dessus
-		if err != nil {
+	if path == "-" || path == "" {
+		return encodeJSON(os.Stdout, data)
+	}
+
+	path, err := resolveOutput(path)
+	if err != nil {
+		return err
+	}
+	if err := validateOutputPath(path); err != nil {
+		return fmt.Errorf("output path validation failed: %w", err)
+	}
+	mode := os.FileMode(0o666) // réduit par l'umask, comme os.Create
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := createTemp(path, mode)
+	if err != nil {
+		return err
+	}
+	// On supprime le fichier temporaire en cas d'échec (sans effet après le renommage)
+	defer os.Remove(tmp.Name())
+
+	if err := encodeJSON(tmp, data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if mode != 0o666 {
+		if err := tmp.Chmod(mode); err != nil {
+			tmp.Close()
 			return err
 		}
-		defer file.Close()
-		enc = json.NewEncoder(file)
 	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Dernière chance d'abandonner sans toucher à la destination
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("output cancelled: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// resolveOutput suit les liens symboliques du chemin de sortie, même vers une cible
+// qui n'existe pas encore, pour que le renommage remplace la cible et non le lien.
+func resolveOutput(path string) (string, error) {
+	for i := 0; i < 40; i++ {
+		info, err := os.Lstat(path)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("%s: too many levels of symbolic links", path)
+}
+
+// createTemp crée un fichier temporaire à côté de path. Contrairement à os.CreateTemp
+// (0600), le fichier reçoit les droits mode réduits par l'umask.
+func createTemp(path string, mode os.FileMode) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("%s%d-%d", prefix, os.Getpid(), time.Now().UnixNano()+int64(i))
+		file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode) // #nosec G304 - chemin de sortie validé
+		if os.IsExist(err) && i < 100 {
+			continue
+		}
+		return file, err
+	}
+}
+
+// encodeJSON encode les données en JSON indenté.
+func encodeJSON(w io.Writer, data any) error {
+	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	if err := enc.Encode(data); err != nil {
 		return fmt.Errorf("json encode: %w", err)
@@ -79,11 +169,31 @@ func writeJSON(path string, data any) error {
 // Write écrit le résultat dans le chemin de fichier donné ("-" signifie stdout).
 // path est le chemin de sortie, doc est le résultat à écrire.
 func Write(path string, doc DocumentResult) error {
-	return writeJSON(path, doc)
+	return WriteContext(context.Background(), path, doc)
+}
+
+// WriteContext écrit le résultat comme Write, en abandonnant l'écriture si le contexte est annulé.
+func WriteContext(ctx context.Context, path string, doc DocumentResult) error {
+	return writeJSON(ctx, path, doc)
+}
+
+// WriteDocumentsContext écrit les résultats de plusieurs documents sous forme de tableau JSON,
+// en abandonnant l'écriture si le contexte est annulé.
+func WriteDocumentsContext(ctx context.Context, path string, docs []DocumentResult) error {
+	if docs == nil {
+		docs = []DocumentResult{}
+	}
+	return writeJSON(ctx, path, docs)
 }
 
 // WriteStructured écrit le résultat structuré dans le chemin de fichier donné ("-" signifie stdout).
 // path est le chemin de sortie, doc est le résultat à écrire.
 func WriteStructured(path string, doc StructuredResult) error {
-	return writeJSON(path, doc)
+	return WriteStructuredContext(context.Background(), path, doc)
+}
+
+// WriteStructuredContext écrit le résultat structuré comme WriteStructured,
+// en abandonnant l'écriture si le contexte est annulé.
+func WriteStructuredContext(ctx context.Context, path string, doc StructuredResult) error {
+	return writeJSON(ctx, path, doc)
 }
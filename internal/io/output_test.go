@@ -1,8 +1,11 @@
 package io
 
 import (
+	"context"
+	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -103,3 +106,111 @@ func TestWriteStructured(t *testing.T) {
 
 	os.Remove(tmp.Name())
 }
+
+func TestWriteContextCancelledKeepsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	if err := os.WriteFile(path, []byte("previous"), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	doc := DocumentResult{URL: "http://example.com"}
+	if err := WriteContext(ctx, path, doc); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != "previous" {
+		t.Fatalf("existing output was modified: %s", data)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("expected no leftover temporary file, found %d entries", len(entries))
+	}
+}
+
+func TestWriteKeepsModeAndSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "results.json")
+	if err := os.WriteFile(target, []byte("previous"), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	link := filepath.Join(dir, "out.json")
+	if err := os.Symlink("results.json", link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := Write(link, DocumentResult{URL: "http://example.com"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("symlink replaced by a regular file")
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600 to be kept, got %v", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(target); !strings.Contains(string(data), "example.com") {
+		t.Errorf("symlink target not updated: %s", data)
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pages.ndjson")
+	stream, err := NewStreamWriter(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, url := range []string{"https://example.com/a", "https://example.com/b"} {
+		if err := stream.Write(DocumentResult{URL: url, Results: []Result{}}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"url":"https://example.com/b"`) {
+		t.Fatalf("expected one compact JSON object per line, got %q", data)
+	}
+}
+
+func TestResumeStreamWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pages.ndjson")
+	stream, _ := NewStreamWriter(path)
+	stream.Write(DocumentResult{URL: "https://example.com/a"})
+	checkpoint := stream.Offset()
+	stream.Write(DocumentResult{URL: "https://example.com/b"}) // écrit après le point de reprise
+	stream.Close()
+
+	resumed, err := ResumeStreamWriter(path, checkpoint)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	resumed.Write(DocumentResult{URL: "https://example.com/c"})
+	resumed.Close()
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "/a") || !strings.Contains(lines[1], "/c") {
+		t.Fatalf("expected records after the checkpoint to be replaced, got %q", data)
+	}
+	if resumed.Offset() != int64(len(data)) {
+		t.Errorf("offset %d does not match file size %d", resumed.Offset(), len(data))
+	}
+
+	if _, err := ResumeStreamWriter(path, int64(len(data))+1); err == nil {
+		t.Error("expected an error when the output is shorter than the checkpoint")
+	}
+}
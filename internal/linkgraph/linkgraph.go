@@ -0,0 +1,179 @@
+// Package linkgraph exporte le graphe des liens découverts par un crawl aux formats
+// DOT (Graphviz), GraphML et CSV (liste d'arêtes).
+package linkgraph
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Edge est un lien d'une page vers une URL.
+type Edge struct {
+	Source string `json:"source"` // page contenant le lien
+	Target string `json:"target"` // URL absolue visée, sans fragment
+	Text   string `json:"text,omitempty"`
+	Rel    string `json:"rel,omitempty"`
+
+	External bool `json:"external,omitempty"` // cible sur un autre site (domaine enregistrable)
+}
+
+// Format est un format d'export du graphe.
+type Format string
+
+const (
+	FormatDOT     Format = "dot"
+	FormatGraphML Format = "graphml"
+	FormatCSV     Format = "csv"
+)
+
+// FormatFromPath déduit le format de l'extension du fichier : .dot ou .gv, .graphml, .csv.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return FormatDOT, nil
+	case ".graphml":
+		return FormatGraphML, nil
+	case ".csv":
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("unknown graph format for %s (expected .dot, .gv, .graphml or .csv)", path)
+}
+
+// WriteFile écrit le graphe dans le fichier, au format donné par son extension.
+func WriteFile(path string, edges []Edge) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path) // #nosec G304 - chemin choisi par l'utilisateur
+	if err != nil {
+		return err
+	}
+	if err := Write(file, format, edges); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Write écrit le graphe au format donné.
+func Write(w io.Writer, format Format, edges []Edge) error {
+	switch format {
+	case FormatDOT:
+		return writeDOT(w, edges)
+	case FormatGraphML:
+		return writeGraphML(w, edges)
+	case FormatCSV:
+		return writeCSV(w, edges)
+	}
+	return fmt.Errorf("unknown graph format %q", format)
+}
+
+// writeDOT écrit un graphe orienté Graphviz, le texte du lien servant d'étiquette ; les
+// liens externes sont en pointillés.
+func writeDOT(w io.Writer, edges []Edge) error {
+	var b strings.Builder
+	b.WriteString("digraph links {\n")
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.Source), dotQuote(e.Target))
+		var attrs []string
+		if e.Text != "" {
+			attrs = append(attrs, "label="+dotQuote(e.Text))
+		}
+		if e.Rel != "" {
+			attrs = append(attrs, "rel="+dotQuote(e.Rel))
+		}
+		if e.External {
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote retourne un identifiant DOT entre guillemets.
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "").Replace(s)
+	return `"` + s + `"`
+}
+
+// writeGraphML écrit un graphe GraphML : un nœud par URL (clé "url") et une arête par
+// lien (clés "text", "rel" et "external").
+func writeGraphML(w io.Writer, edges []Edge) error {
+	ids := map[string]string{}
+	var nodes []string
+	node := func(url string) string {
+		if id, ok := ids[url]; ok {
+			return id
+		}
+		id := fmt.Sprintf("n%d", len(nodes))
+		ids[url] = id
+		nodes = append(nodes, url)
+		return id
+	}
+	type edgeIDs struct{ source, target string }
+	refs := make([]edgeIDs, len(edges))
+	for i, e := range edges {
+		refs[i] = edgeIDs{node(e.Source), node(e.Target)}
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	b.WriteString(`  <key id="url" for="node" attr.name="url" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="text" for="edge" attr.name="text" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="rel" for="edge" attr.name="rel" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="external" for="edge" attr.name="external" attr.type="boolean"><default>false</default></key>` + "\n")
+	b.WriteString(`  <graph id="links" edgedefault="directed">` + "\n")
+	for i, url := range nodes {
+		fmt.Fprintf(&b, `    <node id="n%d"><data key="url">%s</data></node>`+"\n", i, xmlEscape(url))
+	}
+	for i, e := range edges {
+		fmt.Fprintf(&b, `    <edge id="e%d" source="%s" target="%s">`, i, refs[i].source, refs[i].target)
+		if e.Text != "" {
+			fmt.Fprintf(&b, `<data key="text">%s</data>`, xmlEscape(e.Text))
+		}
+		if e.Rel != "" {
+			fmt.Fprintf(&b, `<data key="rel">%s</data>`, xmlEscape(e.Rel))
+		}
+		if e.External {
+			b.WriteString(`<data key="external">true</data>`)
+		}
+		b.WriteString("</edge>\n")
+	}
+	b.WriteString("  </graph>\n</graphml>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// xmlEscape échappe le texte pour un contenu XML.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// writeCSV écrit la liste des arêtes avec un en-tête source,target,text,rel,external.
+func writeCSV(w io.Writer, edges []Edge) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"source", "target", "text", "rel", "external"})
+	for _, e := range edges {
+		external := "false"
+		if e.External {
+			external = "true"
+		}
+		_ = cw.Write([]string{e.Source, e.Target, e.Text, e.Rel, external})
+	}
+	cw.Flush()
+	return cw.Error()
+}
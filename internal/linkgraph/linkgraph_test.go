@@ -0,0 +1,79 @@
+package linkgraph
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+var edges = []Edge{
+	{Source: "https://example.com/", Target: "https://example.com/a", Text: `Say "hi"`, Rel: "nofollow"},
+	{Source: "https://example.com/a", Target: "https://example.com/?q=1&r=2", External: true},
+}
+
+func TestWriteDOT(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, FormatDOT, edges); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := `digraph links {
+  "https://example.com/" -> "https://example.com/a" [label="Say \"hi\"", rel="nofollow"];
+  "https://example.com/a" -> "https://example.com/?q=1&r=2" [style=dashed];
+}
+`
+	if b.String() != want {
+		t.Errorf("unexpected DOT output:\n%s", b.String())
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, FormatGraphML, edges); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var doc struct {
+		Nodes []struct {
+			ID  string `xml:"id,attr"`
+			URL string `xml:"data"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+			Target string `xml:"target,attr"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal([]byte(b.String()), &doc); err != nil {
+		t.Fatalf("invalid GraphML: %v\n%s", err, b.String())
+	}
+	if len(doc.Nodes) != 3 || doc.Nodes[2].URL != "https://example.com/?q=1&r=2" {
+		t.Errorf("expected one node per URL, got %+v", doc.Nodes)
+	}
+	if len(doc.Edges) != 2 || doc.Edges[1].Source != "n1" || doc.Edges[1].Target != "n2" {
+		t.Errorf("unexpected edges: %+v", doc.Edges)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, FormatCSV, edges); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != "source,target,text,rel,external" || records[1][2] != `Say "hi"` || records[2][4] != "true" {
+		t.Errorf("unexpected records: %q", records)
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	for path, want := range map[string]Format{"links.dot": FormatDOT, "links.GV": FormatDOT, "g.graphml": FormatGraphML, "edges.csv": FormatCSV} {
+		if got, err := FormatFromPath(path); err != nil || got != want {
+			t.Errorf("%s: got %q (%v), want %q", path, got, err, want)
+		}
+	}
+	if _, err := FormatFromPath("links.json"); err == nil {
+		t.Error("expected an error for an unknown extension")
+	}
+}
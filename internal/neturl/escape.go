@@ -0,0 +1,81 @@
+package neturl
+
+import (
+	"errors"
+	"strings"
+)
+
+const upperHex = "0123456789ABCDEF"
+
+// Unescape décode les séquences %XX d'une chaîne. Le caractère '+' est conservé tel quel.
+func Unescape(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return "", errors.New("invalid percent-encoding")
+		}
+		b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// EscapePath encode un chemin pour l'insérer dans une URL en conservant les '/'.
+func EscapePath(p string) string {
+	var b strings.Builder
+	b.Grow(len(p))
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if isUnreserved(c) || isSubDelim(c) || c == ':' || c == '@' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(upperHex[c>>4])
+		b.WriteByte(upperHex[c&15])
+	}
+	return b.String()
+}
+
+// PathEscape encode une chaîne pour l'insérer comme segment de chemin : contrairement
+// à EscapePath, les '/' sont encodés.
+func PathEscape(s string) string {
+	return strings.ReplaceAll(EscapePath(s), "/", "%2F")
+}
+
+// isUnreserved retourne true pour les caractères non réservés de la RFC 3986.
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// isSubDelim retourne true pour les sous-délimiteurs de la RFC 3986.
+func isSubDelim(c byte) bool {
+	return strings.IndexByte("!$&'()*+,;=", c) >= 0
+}
+
+// isHex retourne true si c est un chiffre hexadécimal.
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// unhex convertit un chiffre hexadécimal en valeur.
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
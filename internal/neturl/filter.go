@@ -0,0 +1,131 @@
+package neturl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Filter décide quelles URLs découvertes peuvent être suivies, à partir de règles
+// d'inclusion et d'exclusion appliquées à la forme normalisée de l'URL.
+//
+// Un motif préfixé par "re:" est une expression régulière cherchée dans l'URL complète.
+// Les autres motifs sont des globs où '*' correspond à n'importe quelle séquence (les
+// autres caractères, '?' compris, sont littéraux) : ancrés sur l'URL complète, ou sur le
+// chemin et la requête s'ils commencent par '/'.
+type Filter struct {
+	rules   Rules
+	include []pattern
+	exclude []pattern
+}
+
+// pattern est un motif compilé.
+type pattern struct {
+	raw  string
+	re   *regexp.Regexp // nil pour un glob
+	path bool           // glob appliqué au chemin et à la requête
+}
+
+// NewFilter compile les motifs d'inclusion et d'exclusion.
+func NewFilter(include, exclude []string, rules Rules) (*Filter, error) {
+	f := &Filter{rules: rules}
+	for _, list := range []struct {
+		raw []string
+		dst *[]pattern
+	}{{include, &f.include}, {exclude, &f.exclude}} {
+		for _, raw := range list.raw {
+			p, err := compilePattern(raw)
+			if err != nil {
+				return nil, err
+			}
+			*list.dst = append(*list.dst, p)
+		}
+	}
+	return f, nil
+}
+
+// compilePattern analyse un motif de filtre.
+func compilePattern(raw string) (pattern, error) {
+	if expr, ok := strings.CutPrefix(raw, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return pattern{}, fmt.Errorf("invalid pattern %q: %w", raw, err)
+		}
+		return pattern{raw: raw, re: re}, nil
+	}
+	if raw == "" {
+		return pattern{}, fmt.Errorf("empty pattern")
+	}
+	return pattern{raw: raw, path: strings.HasPrefix(raw, "/")}, nil
+}
+
+// match retourne true si le motif correspond à l'URL normalisée.
+func (p pattern) match(u *URL) bool {
+	if p.re != nil {
+		return p.re.MatchString(u.String())
+	}
+	if p.path {
+		target := u.Path
+		if u.RawQuery != "" {
+			target += "?" + u.RawQuery
+		}
+		return matchGlob(p.raw, target)
+	}
+	return matchGlob(p.raw, u.String())
+}
+
+// Allows retourne true si l'URL peut être suivie. Un Filter nil accepte toutes les URLs.
+func (f *Filter) Allows(rawurl string) bool {
+	return f.Explain(rawurl) == ""
+}
+
+// Explain retourne la raison pour laquelle l'URL est écartée, ou "" si elle peut être suivie :
+// elle ne doit correspondre à aucune exclusion et, s'il y a des inclusions, à l'une d'elles.
+func (f *Filter) Explain(rawurl string) string {
+	if f == nil || (len(f.include) == 0 && len(f.exclude) == 0) {
+		return ""
+	}
+	u, err := Parse(rawurl)
+	if err != nil {
+		return "invalid URL"
+	}
+	u = u.Normalize(f.rules)
+	for _, p := range f.exclude {
+		if p.match(u) {
+			return fmt.Sprintf("excluded by %q", p.raw)
+		}
+	}
+	if len(f.include) == 0 {
+		return ""
+	}
+	for _, p := range f.include {
+		if p.match(u) {
+			return ""
+		}
+	}
+	return "not matched by any include rule"
+}
+
+// matchGlob vérifie si toute la chaîne correspond au glob, '*' correspondant à
+// n'importe quelle séquence.
+func matchGlob(glob, s string) bool {
+	parts := strings.Split(glob, "*")
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	if len(parts) == 1 {
+		return s == parts[0]
+	}
+	pos := len(parts[0])
+
+	// Les segments intermédiaires sont cherchés au plus tôt, le dernier ancre la fin
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(s[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	last := parts[len(parts)-1]
+	return len(s)-pos >= len(last) && strings.HasSuffix(s, last)
+}
@@ -0,0 +1,44 @@
+package neturl
+
+import "testing"
+
+func TestFilter(t *testing.T) {
+	f, err := NewFilter(
+		[]string{"/products/*", "re:^https://shop\\.example\\.com/"},
+		[]string{"/cart*", "*?sort=*", "*&sort=*", "*.pdf"},
+		DefaultRules,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/products/shoes", true},
+		{"https://EXAMPLE.com/products/shoes#reviews", true},
+		{"https://example.com/products/shoes?sort=price", false},
+		{"https://example.com/products/shoes?utm_source=x&sort=price", false},
+		{"https://example.com/products/manual.pdf", false},
+		{"https://example.com/cart", false},
+		{"https://example.com/about", false},
+		{"https://shop.example.com/anything", true},
+		{"https://example.com/products", false}, // le '/' final est retiré par la normalisation
+	}
+	for _, tt := range tests {
+		if got := f.Allows(tt.url); got != tt.want {
+			t.Errorf("%s: got %v (%s), want %v", tt.url, got, f.Explain(tt.url), tt.want)
+		}
+	}
+
+	if reason := f.Explain("https://example.com/cart/items"); reason != `excluded by "/cart*"` {
+		t.Errorf("unexpected reason: %q", reason)
+	}
+	var none *Filter
+	if !none.Allows("https://example.com/cart") {
+		t.Error("a nil filter should allow every URL")
+	}
+	if _, err := NewFilter(nil, []string{"re:("}, DefaultRules); err == nil {
+		t.Error("expected an error for an invalid regular expression")
+	}
+}
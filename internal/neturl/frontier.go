@@ -0,0 +1,115 @@
+package neturl
+
+import "sort"
+
+// FrontierEntry est une URL en attente de visite, avec sa distance à l'URL de départ.
+type FrontierEntry struct {
+	URL   string `json:"url"`
+	Depth int    `json:"depth"`
+}
+
+// Frontier est la file des URLs à visiter d'un parcours. Une URL n'y entre qu'une fois :
+// les URLs sont comparées sous leur forme normalisée, et celles déjà visitées (URL finale
+// d'une redirection par exemple) peuvent être marquées pour ne jamais y entrer.
+type Frontier struct {
+	rules Rules
+	seen  map[string]bool
+	queue []FrontierEntry
+}
+
+// NewFrontier retourne une Frontier vide qui normalise les URLs selon rules.
+func NewFrontier(rules Rules) *Frontier {
+	return &Frontier{rules: rules, seen: map[string]bool{}}
+}
+
+// Key retourne la forme normalisée servant à comparer les URLs. Une URL invalide
+// est comparée telle quelle.
+func (f *Frontier) Key(rawurl string) string {
+	if key, err := Normalize(rawurl, f.rules); err == nil {
+		return key
+	}
+	return rawurl
+}
+
+// Push ajoute l'URL en fin de file si elle n'a jamais été vue et retourne true dans ce cas.
+func (f *Frontier) Push(rawurl string, depth int) bool {
+	key := f.Key(rawurl)
+	if f.seen[key] {
+		return false
+	}
+	f.seen[key] = true
+	f.queue = append(f.queue, FrontierEntry{URL: rawurl, Depth: depth})
+	return true
+}
+
+// MarkSeen empêche l'URL d'entrer dans la file et retourne false si elle avait déjà été vue.
+func (f *Frontier) MarkSeen(rawurl string) bool {
+	key := f.Key(rawurl)
+	if f.seen[key] {
+		return false
+	}
+	f.seen[key] = true
+	return true
+}
+
+// Seen retourne true si l'URL est déjà entrée dans la file ou a été marquée.
+func (f *Frontier) Seen(rawurl string) bool {
+	return f.seen[f.Key(rawurl)]
+}
+
+// Pop retire la première URL de la file.
+func (f *Frontier) Pop() (FrontierEntry, bool) {
+	if len(f.queue) == 0 {
+		return FrontierEntry{}, false
+	}
+	entry := f.queue[0]
+	f.queue = f.queue[1:]
+	return entry, true
+}
+
+// Len retourne le nombre d'URLs en attente.
+func (f *Frontier) Len() int {
+	return len(f.queue)
+}
+
+// FrontierState est l'état sérialisable d'une Frontier : les formes normalisées déjà vues
+// et la file d'attente.
+type FrontierState struct {
+	Seen  []string        `json:"seen"`
+	Queue []FrontierEntry `json:"queue"`
+}
+
+// State retourne une copie de l'état de la Frontier, clés triées.
+func (f *Frontier) State() FrontierState {
+	seen := make([]string, 0, len(f.seen))
+	for key := range f.seen {
+		seen = append(seen, key)
+	}
+	sort.Strings(seen)
+	return FrontierState{Seen: seen, Queue: append([]FrontierEntry(nil), f.queue...)}
+}
+
+// RestoreFrontier reconstruit une Frontier à partir d'un état obtenu par State
+// avec les mêmes règles.
+func RestoreFrontier(rules Rules, state FrontierState) *Frontier {
+	f := NewFrontier(rules)
+	for _, key := range state.Seen {
+		f.seen[key] = true
+	}
+	f.queue = append(f.queue, state.Queue...)
+	return f
+}
+
+// Dedupe retourne les URLs sans doublons, dans l'ordre de première apparition. La forme
+// normalisée ne sert qu'à repérer les doublons : chaque URL est retournée telle qu'elle
+// est apparue la première fois.
+func Dedupe(urls []string, rules Rules) []string {
+	f := NewFrontier(rules)
+	var out []string
+	for _, u := range urls {
+		if f.MarkSeen(u) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
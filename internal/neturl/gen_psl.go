@@ -0,0 +1,96 @@
+//go:build ignore
+
+// gen_psl génère publicsuffix.dat, la liste des suffixes publics embarquée par neturl,
+// à partir d'une copie locale de https://publicsuffix.org/list/public_suffix_list.dat :
+//
+//	go run gen_psl.go -in public_suffix_list.dat
+//
+// Les règles sont conservées dans leur ordre, en ASCII (punycode), sans les commentaires.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"webextractor/internal/neturl"
+)
+
+func main() {
+	in := flag.String("in", "public_suffix_list.dat", "local copy of the public suffix list")
+	out := flag.String("out", "publicsuffix.dat", "generated rule file")
+	flag.Parse()
+
+	if err := generate(*in, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "gen_psl:", err)
+		os.Exit(1)
+	}
+}
+
+// generate lit la liste au format de publicsuffix.org et écrit une règle par ligne.
+func generate(in, out string) error {
+	file, err := os.Open(in) // #nosec G304 - chemin choisi par l'utilisateur
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var rules []string
+	seen := map[string]bool{}
+	private := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.Contains(line, "===BEGIN PRIVATE DOMAINS===") {
+			private = true
+		}
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		rule, err := asciiRule(strings.Fields(line)[0])
+		if err != nil {
+			return fmt.Errorf("%s: %w", line, err)
+		}
+		if private {
+			rule += " private"
+		}
+		if !seen[rule] {
+			seen[rule] = true
+			rules = append(rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return fmt.Errorf("no rule found in %s", in)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Généré par gen_psl.go à partir de %s (%d règles). Ne pas modifier.\n", filepath.Base(in), len(rules))
+	b.WriteString("// Source : https://publicsuffix.org/list/, sous licence MPL 2.0.\n")
+	for _, rule := range rules {
+		b.WriteString(rule)
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(out, []byte(b.String()), 0o644)
+}
+
+// asciiRule convertit une règle ("*.ck", "!www.ck", "公司.cn") en ASCII et en minuscules.
+func asciiRule(rule string) (string, error) {
+	prefix := ""
+	switch {
+	case strings.HasPrefix(rule, "!"):
+		prefix, rule = "!", rule[1:]
+	case strings.HasPrefix(rule, "*."):
+		prefix, rule = "*.", rule[2:]
+	}
+	ascii, err := neturl.ToASCII(strings.ToLower(rule))
+	if err != nil {
+		return "", err
+	}
+	return prefix + strings.ToLower(ascii), nil
+}
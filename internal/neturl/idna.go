@@ -0,0 +1,332 @@
+package neturl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// acePrefix marque un label encodé en punycode (RFC 3490).
+const acePrefix = "xn--"
+
+// Paramètres de punycode (RFC 3492, section 5).
+const (
+	punyBase    = 36
+	punyTMin    = 1
+	punyTMax    = 26
+	punySkew    = 38
+	punyDamp    = 700
+	initialBias = 72
+	initialN    = 128
+)
+
+// labelSeparators sont les points reconnus comme séparateurs de labels (RFC 3490, section 3.1).
+var labelSeparators = strings.NewReplacer("。", ".", "．", ".", "｡", ".")
+
+// ToASCII convertit un nom d'hôte Unicode ("müller.de") en sa forme ASCII
+// ("xn--mller-kva.de"). Les labels sont mis en minuscules ; les labels déjà ASCII
+// sont conservés. Un label commençant ou finissant par un tiret, ou un label "xn--" qui
+// n'est pas un punycode valide, est une erreur. Le mappage complet d'UTS #46
+// (normalisation NFC, caractères interdits) n'est pas appliqué.
+func ToASCII(host string) (string, error) {
+	labels := strings.Split(labelSeparators.Replace(host), ".")
+	for i, label := range labels {
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "", fmt.Errorf("invalid host %q: label %q starts or ends with a hyphen", host, label)
+		}
+		if isASCII(label) {
+			if !validACELabel(label) {
+				return "", fmt.Errorf("invalid host %q: label %q is not valid punycode", host, label)
+			}
+			continue
+		}
+		if !utf8.ValidString(label) {
+			return "", fmt.Errorf("invalid UTF-8 in host %q", host)
+		}
+		encoded, err := punyEncode(strings.ToLower(label))
+		if err != nil {
+			return "", fmt.Errorf("invalid host %q: %w", host, err)
+		}
+		labels[i] = acePrefix + encoded
+		if len(labels[i]) > 63 {
+			return "", fmt.Errorf("invalid host %q: label too long", host)
+		}
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// validACELabel retourne false pour un label "xn--" qui ne se décode pas, ou dont
+// l'encodage n'est pas celui que produirait ToASCII (casse mise à part).
+func validACELabel(label string) bool {
+	if len(label) < len(acePrefix) || !strings.EqualFold(label[:len(acePrefix)], acePrefix) {
+		return true
+	}
+	encoded := strings.ToLower(label[len(acePrefix):])
+	decoded, err := punyDecode(encoded)
+	if err != nil || isASCII(decoded) {
+		return false
+	}
+	again, err := punyEncode(strings.ToLower(decoded))
+	return err == nil && again == encoded
+}
+
+// ToUnicode convertit les labels punycode d'un nom d'hôte ("xn--mller-kva.de") en
+// Unicode ("müller.de"). Un label qui ne se décode pas est conservé tel quel.
+func ToUnicode(host string) string {
+	if !strings.Contains(strings.ToLower(host), acePrefix) {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if len(label) > len(acePrefix) && strings.EqualFold(label[:len(acePrefix)], acePrefix) {
+			if decoded, err := punyDecode(strings.ToLower(label[len(acePrefix):])); err == nil {
+				labels[i] = decoded
+			}
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// HostToASCII convertit l'hôte d'une autorité ("müller.de:8080", éventuellement encodé
+// en %XX) en ASCII, port conservé. Les littéraux IP sont inchangés.
+func HostToASCII(hostport string) (string, error) {
+	if strings.HasPrefix(hostport, "[") {
+		return hostport, nil
+	}
+	host, port := splitHostPort(hostport)
+	host, err := Unescape(host)
+	if err != nil {
+		return "", err
+	}
+	if host, err = ToASCII(host); err != nil {
+		return "", err
+	}
+	if port != "" || strings.HasSuffix(hostport, ":") {
+		host += ":" + port
+	}
+	return host, nil
+}
+
+// HostToUnicode convertit l'hôte d'une autorité en Unicode, port conservé.
+func HostToUnicode(hostport string) string {
+	if strings.HasPrefix(hostport, "[") {
+		return hostport
+	}
+	host, port := splitHostPort(hostport)
+	if port != "" || strings.HasSuffix(hostport, ":") {
+		return ToUnicode(host) + ":" + port
+	}
+	return ToUnicode(host)
+}
+
+// ASCIIURL retourne l'URL avec son hôte en ASCII. Une URL invalide ou dont l'hôte est
+// déjà ASCII est retournée inchangée.
+func ASCIIURL(rawurl string) string {
+	u, err := ParseReference(rawurl)
+	if err != nil || u.Host == "" {
+		return rawurl
+	}
+	host, err := HostToASCII(u.Host)
+	if err != nil || host == u.Host {
+		return rawurl
+	}
+	u.Host = host
+	return u.String()
+}
+
+// UnicodeURL retourne l'URL avec son hôte en Unicode, pour l'affichage.
+func UnicodeURL(rawurl string) string {
+	u, err := ParseReference(rawurl)
+	if err != nil || u.Host == "" {
+		return rawurl
+	}
+	host, err := HostToASCII(u.Host) // un hôte encodé en %XX est d'abord décodé
+	if err != nil {
+		return rawurl
+	}
+	host = HostToUnicode(host)
+	if host == u.Host {
+		return rawurl
+	}
+	u.Host = host
+	return u.String()
+}
+
+// isASCII retourne true si la chaîne ne contient que des caractères ASCII.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// errPunyOverflow est retournée quand un label dépasse les capacités de punycode.
+var errPunyOverflow = errors.New("punycode overflow")
+
+// punyEncode encode un label Unicode en punycode, sans préfixe (RFC 3492, section 6.3).
+func punyEncode(label string) (string, error) {
+	input := []rune(label)
+	var out strings.Builder
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			out.WriteByte(byte(r))
+		}
+	}
+	basic := out.Len()
+	handled := basic
+	if basic > 0 {
+		out.WriteByte('-')
+	}
+
+	n, delta, bias := rune(initialN), 0, initialBias
+	for handled < len(input) {
+		// Plus petit point de code non encore traité
+		m := rune(math.MaxInt32)
+		for _, r := range input {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		if int(m-n) > (math.MaxInt32-delta)/(handled+1) {
+			return "", errPunyOverflow
+		}
+		delta += int(m-n) * (handled + 1)
+		n = m
+
+		for _, r := range input {
+			if r < n {
+				delta++
+				if delta == math.MaxInt32 {
+					return "", errPunyOverflow
+				}
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := punyThreshold(k, bias)
+				if q < t {
+					break
+				}
+				out.WriteByte(punyDigit(t + (q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			out.WriteByte(punyDigit(q))
+			bias = punyAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return out.String(), nil
+}
+
+// punyDecode décode un label punycode sans préfixe (RFC 3492, section 6.2).
+func punyDecode(encoded string) (string, error) {
+	var output []rune
+	if pos := strings.LastIndexByte(encoded, '-'); pos >= 0 {
+		for i := 0; i < pos; i++ {
+			if encoded[i] >= utf8.RuneSelf {
+				return "", errors.New("invalid punycode")
+			}
+			output = append(output, rune(encoded[i]))
+		}
+		encoded = encoded[pos+1:]
+	}
+
+	n, i, bias := rune(initialN), 0, initialBias
+	for len(encoded) > 0 {
+		oldi, w := i, 1
+		for k := punyBase; ; k += punyBase {
+			if len(encoded) == 0 {
+				return "", errors.New("invalid punycode")
+			}
+			digit, ok := punyValue(encoded[0])
+			if !ok {
+				return "", errors.New("invalid punycode")
+			}
+			encoded = encoded[1:]
+			if digit > (math.MaxInt32-i)/w {
+				return "", errPunyOverflow
+			}
+			i += digit * w
+			t := punyThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			if w > math.MaxInt32/(punyBase-t) {
+				return "", errPunyOverflow
+			}
+			w *= punyBase - t
+		}
+		length := len(output) + 1
+		bias = punyAdapt(i-oldi, length, oldi == 0)
+		if i/length > math.MaxInt32-int(n) {
+			return "", errPunyOverflow
+		}
+		n += rune(i / length)
+		i %= length
+		if n > utf8.MaxRune {
+			return "", errors.New("invalid punycode")
+		}
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = n
+		i++
+	}
+	return string(output), nil
+}
+
+// punyThreshold borne le seuil t d'un chiffre entre tmin et tmax.
+func punyThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punyTMin
+	case k >= bias+punyTMax:
+		return punyTMax
+	}
+	return k - bias
+}
+
+// punyAdapt calcule le nouveau biais (RFC 3492, section 6.1).
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+// punyDigit retourne le caractère d'un chiffre punycode (a-z puis 0-9).
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+// punyValue retourne la valeur d'un chiffre punycode.
+func punyValue(c byte) (int, bool) {
+	switch {
+	case 'a' <= c && c <= 'z':
+		return int(c - 'a'), true
+	case 'A' <= c && c <= 'Z':
+		return int(c - 'A'), true
+	case '0' <= c && c <= '9':
+		return int(c-'0') + 26, true
+	}
+	return 0, false
+}
@@ -0,0 +1,79 @@
+package neturl
+
+import "testing"
+
+func TestPunycode(t *testing.T) {
+	// Exemples de la RFC 3492, section 7.1, et labels courants
+	tests := []struct{ unicode, ascii string }{
+		{"müller", "mller-kva"},
+		{"bücher", "bcher-kva"},
+		{"münchen", "mnchen-3ya"},
+		{"他们为什么不说中文", "ihqwcrb4cv8a8dqg056pqjye"},
+		{"почемужеонинеговорятпорусски", "b1abfaaepdrnnbgefbadotcwatmq2g4l"},
+		{"3年B組金八先生", "3B-ww4c5e180e575a65lsy2b"},
+		{"ليهمابتكلموشعربي؟", "egbpdaj6bu4bxfgehfvwxn"},
+	}
+	for _, tt := range tests {
+		encoded, err := punyEncode(tt.unicode)
+		if err != nil || encoded != tt.ascii {
+			t.Errorf("punyEncode(%q) = %q (%v), want %q", tt.unicode, encoded, err, tt.ascii)
+		}
+		decoded, err := punyDecode(tt.ascii)
+		if err != nil || decoded != tt.unicode {
+			t.Errorf("punyDecode(%q) = %q (%v), want %q", tt.ascii, decoded, err, tt.unicode)
+		}
+	}
+	if _, err := punyDecode("mller-kv!"); err == nil {
+		t.Error("expected an error for an invalid digit")
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	tests := []struct{ unicode, ascii string }{
+		{"müller.de", "xn--mller-kva.de"},
+		{"MÜLLER.de", "xn--mller-kva.de"},
+		{"www.例え.テスト", "www.xn--r8jz45g.xn--zckzah"},
+		{"bücher。example", "xn--bcher-kva.example"},
+		{"example.com", "example.com"},
+	}
+	for _, tt := range tests {
+		if got, err := ToASCII(tt.unicode); err != nil || got != tt.ascii {
+			t.Errorf("ToASCII(%q) = %q (%v), want %q", tt.unicode, got, err, tt.ascii)
+		}
+	}
+	for _, host := range []string{"-a.com", "a-.com", "xn--zz.com", "xn--mller-kva-.de"} {
+		if got, err := ToASCII(host); err == nil {
+			t.Errorf("ToASCII(%q) = %q, expected an error", host, got)
+		}
+	}
+	if _, err := Normalize("https://xn--zz.com/", DefaultRules); err == nil {
+		t.Errorf("expected an invalid host not to normalize")
+	}
+	if got := ToUnicode("www.XN--MLLER-KVA.de"); got != "www.müller.de" {
+		t.Errorf("ToUnicode: got %q", got)
+	}
+	if got := ToUnicode("xn--invalid!.de"); got != "xn--invalid!.de" {
+		t.Errorf("an undecodable label should be kept, got %q", got)
+	}
+}
+
+func TestIDNURLs(t *testing.T) {
+	if got := ASCIIURL("https://müller.de:8443/straße?q=ü"); got != "https://xn--mller-kva.de:8443/stra%C3%9Fe?q=%C3%BC" {
+		t.Errorf("ASCIIURL: got %q", got)
+	}
+	if got := ASCIIURL("https://m%C3%BCller.de/"); got != "https://xn--mller-kva.de/" {
+		t.Errorf("percent-encoded host: got %q", got)
+	}
+	if got := UnicodeURL("https://xn--mller-kva.de/a"); got != "https://müller.de/a" {
+		t.Errorf("UnicodeURL: got %q", got)
+	}
+	if got := UnicodeURL("http://[::1]:8080/"); got != "http://[::1]:8080/" {
+		t.Errorf("IPv6 literal changed: %q", got)
+	}
+
+	a, _ := Normalize("https://MÜLLER.de/", DefaultRules)
+	b, _ := Normalize("https://xn--mller-kva.de/", DefaultRules)
+	if a != b || a != "https://xn--mller-kva.de/" {
+		t.Errorf("expected Unicode and punycode hosts to normalize alike, got %q and %q", a, b)
+	}
+}
@@ -0,0 +1,202 @@
+package neturl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rules sélectionne les transformations appliquées par Normalize.
+type Rules uint
+
+const (
+	RuleLowercaseHost   Rules = 1 << iota // "Example.COM" → "example.com"
+	RuleDefaultPort                       // ":80" en http, ":443" en https
+	RuleFragment                          // "#section"
+	RuleSortQuery                         // "?b=2&a=1" → "?a=1&b=2"
+	RuleTrailingSlash                     // "/docs/" → "/docs" (la racine garde son "/")
+	RuleTracking                          // utm_*, fbclid, gclid...
+	RuleDotSegments                       // "/a/./b/../c" → "/a/c"
+	RulePercentEncoding                   // "%7e" → "~", "%2f" → "%2F"
+	RuleIDNA                              // "müller.de" → "xn--mller-kva.de"
+
+	// DefaultRules applique toutes les transformations.
+	DefaultRules = RuleLowercaseHost | RuleDefaultPort | RuleFragment | RuleSortQuery |
+		RuleTrailingSlash | RuleTracking | RuleDotSegments | RulePercentEncoding | RuleIDNA
+)
+
+// ruleNames associe les noms acceptés par ParseRules aux règles.
+var ruleNames = []struct {
+	name string
+	rule Rules
+}{
+	{"host", RuleLowercaseHost},
+	{"port", RuleDefaultPort},
+	{"fragment", RuleFragment},
+	{"sort-query", RuleSortQuery},
+	{"trailing-slash", RuleTrailingSlash},
+	{"tracking", RuleTracking},
+	{"dot-segments", RuleDotSegments},
+	{"encoding", RulePercentEncoding},
+	{"idna", RuleIDNA},
+}
+
+// ParseRules lit une liste de règles séparées par des virgules ("host,port,fragment"),
+// "all" pour DefaultRules ou "none" pour n'en appliquer aucune.
+func ParseRules(s string) (Rules, error) {
+	var rules Rules
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "all":
+			rules |= DefaultRules
+			continue
+		case "none", "":
+			continue
+		}
+		found := false
+		for _, r := range ruleNames {
+			if r.name == name {
+				rules |= r.rule
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown normalization rule %q", name)
+		}
+	}
+	return rules, nil
+}
+
+// String retourne la liste des règles, au format de ParseRules.
+func (r Rules) String() string {
+	var names []string
+	for _, rn := range ruleNames {
+		if r&rn.rule != 0 {
+			names = append(names, rn.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// trackingParams sont les paramètres de suivi retirés par RuleTracking, en plus des utm_*.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"mc_cid": true, "mc_eid": true, "igshid": true, "_ga": true, "_gl": true,
+}
+
+// isTrackingParam retourne true si la clé de requête est un paramètre de suivi.
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "utm_") || trackingParams[key]
+}
+
+// Normalize retourne la forme canonique de l'URL selon les règles données. Deux URLs
+// désignant la même page à ces différences près ont la même forme normalisée.
+// Avec RuleIDNA, un hôte invalide (voir ToASCII) est une erreur.
+func Normalize(rawurl string, rules Rules) (string, error) {
+	u, err := Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	if rules&RuleIDNA != 0 {
+		if _, err := HostToASCII(u.Host); err != nil {
+			return "", err
+		}
+	}
+	return u.Normalize(rules).String(), nil
+}
+
+// Normalize retourne une copie normalisée de l'URL.
+func (u *URL) Normalize(rules Rules) *URL {
+	n := *u
+
+	if rules&RuleIDNA != 0 {
+		if host, err := HostToASCII(n.Host); err == nil {
+			n.Host = host
+		}
+	}
+	if rules&RuleLowercaseHost != 0 {
+		n.Host = strings.ToLower(n.Host)
+	}
+	if rules&RuleDefaultPort != 0 {
+		if (n.Scheme == "http" && strings.HasSuffix(n.Host, ":80")) || (n.Scheme == "https" && strings.HasSuffix(n.Host, ":443")) {
+			n.Host = n.Host[:strings.LastIndex(n.Host, ":")]
+		}
+	}
+	if rules&RuleFragment != 0 {
+		n.Fragment = ""
+	}
+	if rules&RulePercentEncoding != 0 {
+		n.Path = normalizeEscapes(n.Path)
+		n.RawQuery = normalizeEscapes(n.RawQuery)
+	}
+	if rules&RuleDotSegments != 0 && n.Path != "" {
+		n.Path = removeDotSegments(n.Path)
+	}
+	if rules&RuleTrailingSlash != 0 && len(n.Path) > 1 {
+		n.Path = strings.TrimRight(n.Path, "/")
+		if n.Path == "" {
+			n.Path = "/"
+		}
+	}
+	if rules&(RuleTracking|RuleSortQuery) != 0 && n.RawQuery != "" {
+		n.RawQuery = normalizeQuery(n.RawQuery, rules)
+	}
+	return &n
+}
+
+// normalizeQuery retire les paramètres de suivi et trie les paramètres par clé.
+// L'ordre des valeurs d'une même clé est conservé.
+func normalizeQuery(rawQuery string, rules Rules) string {
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(param, "=")
+		if rules&RuleTracking != 0 {
+			if k, err := Unescape(strings.ReplaceAll(key, "+", " ")); err == nil && isTrackingParam(k) {
+				continue
+			}
+		}
+		params = append(params, param)
+	}
+	if rules&RuleSortQuery != 0 {
+		sort.SliceStable(params, func(i, j int) bool {
+			ki, _, _ := strings.Cut(params[i], "=")
+			kj, _, _ := strings.Cut(params[j], "=")
+			return ki < kj
+		})
+	}
+	return strings.Join(params, "&")
+}
+
+// normalizeEscapes décode les caractères non réservés encodés ("%7E" → "~")
+// et met les autres séquences en majuscules ("%2f" → "%2F").
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			c := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(c) {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('%')
+				b.WriteByte(upperHex[c>>4])
+				b.WriteByte(upperHex[c&15])
+			}
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package neturl
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+		rules    Rules
+	}{
+		{"http://Example.COM/a", "http://example.com/a", RuleLowercaseHost},
+		{"http://example.com:80/a", "http://example.com/a", RuleDefaultPort},
+		{"https://example.com:443/a", "https://example.com/a", RuleDefaultPort},
+		{"http://example.com:443/a", "http://example.com:443/a", RuleDefaultPort},
+		{"http://example.com/a#top", "http://example.com/a", RuleFragment},
+		{"http://example.com/a?b=2&a=1&b=1", "http://example.com/a?a=1&b=2&b=1", RuleSortQuery},
+		{"http://example.com/docs/", "http://example.com/docs", RuleTrailingSlash},
+		{"http://example.com/", "http://example.com/", RuleTrailingSlash},
+		{"http://example.com/a?utm_source=x&id=1&fbclid=y", "http://example.com/a?id=1", RuleTracking},
+		{"http://example.com/a?utm_source=x", "http://example.com/a", RuleTracking},
+		{"http://example.com/a/./b/../c", "http://example.com/a/c", RuleDotSegments},
+		{"http://example.com/%7euser/a%2fb", "http://example.com/~user/a%2Fb", RulePercentEncoding},
+		{"HTTP://Example.com:80/a/./b/?utm_source=x&b=2&a=1#top", "http://example.com/a/b?a=1&b=2", DefaultRules},
+		{"http://Example.com/a#top", "http://Example.com/a#top", 0},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in, tt.rules)
+		if err != nil {
+			t.Fatalf("Normalize(%q): %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q, %s) = %q, want %q", tt.in, tt.rules, got, tt.want)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("host, Fragment,tracking")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rules != RuleLowercaseHost|RuleFragment|RuleTracking || rules.String() != "host,fragment,tracking" {
+		t.Errorf("unexpected rules: %s", rules)
+	}
+	if rules, _ := ParseRules("all"); rules != DefaultRules {
+		t.Errorf("all: got %s", rules)
+	}
+	if rules, _ := ParseRules("none"); rules != 0 || rules.String() != "none" {
+		t.Errorf("none: got %s", rules)
+	}
+	if _, err := ParseRules("host,query"); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
+
+func TestFrontier(t *testing.T) {
+	f := NewFrontier(DefaultRules)
+	if !f.Push("https://example.com/a?b=2&a=1", 0) {
+		t.Fatal("first push should be accepted")
+	}
+	if f.Push("https://EXAMPLE.com:443/a/?a=1&b=2#x", 1) {
+		t.Error("an equivalent URL should be rejected")
+	}
+	if f.MarkSeen("https://example.com/b") != true || f.Push("https://example.com/b/", 1) {
+		t.Error("a URL marked as seen should not enter the queue")
+	}
+	if f.Len() != 1 {
+		t.Fatalf("expected 1 queued URL, got %d", f.Len())
+	}
+	if entry, ok := f.Pop(); !ok || entry.URL != "https://example.com/a?b=2&a=1" || entry.Depth != 0 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if _, ok := f.Pop(); ok {
+		t.Error("queue should be empty")
+	}
+
+	got := Dedupe([]string{"https://example.com/a#one", "https://example.com/b", "https://example.com/a#two"}, DefaultRules)
+	if len(got) != 2 || got[0] != "https://example.com/a#one" || got[1] != "https://example.com/b" {
+		t.Errorf("unexpected dedupe result: %v", got)
+	}
+}
+
+func TestFrontierState(t *testing.T) {
+	f := NewFrontier(DefaultRules)
+	f.Push("https://example.com/a", 0)
+	f.Push("https://example.com/b", 1)
+	f.Pop()
+
+	restored := RestoreFrontier(DefaultRules, f.State())
+	if restored.Len() != 1 || !restored.Seen("https://example.com/a#x") || restored.Push("https://example.com/b/", 2) {
+		t.Fatalf("restored frontier differs: %+v", restored.State())
+	}
+	if entry, _ := restored.Pop(); entry.URL != "https://example.com/b" || entry.Depth != 1 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
@@ -0,0 +1,124 @@
+package neturl
+
+import (
+	_ "embed"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+)
+
+//go:generate go run gen_psl.go -in public_suffix_list.dat
+
+// publicSuffixData est la liste des suffixes publics (https://publicsuffix.org/),
+// générée par gen_psl.go : une règle ASCII par ligne, suivie de " private" pour
+// les domaines déclarés par leurs propriétaires (github.io, blogspot.com...).
+//
+//go:embed publicsuffix.dat
+var publicSuffixData string
+
+// Types de règles de la liste (https://publicsuffix.org/list/ : règles, jokers, exceptions).
+const (
+	suffixRule      = 1 << iota // "co.uk"
+	suffixWildcard              // "*.ck", enregistré sous "ck"
+	suffixException             // "!www.ck", enregistré sous "www.ck"
+	suffixPrivate               // règle de la section PRIVATE DOMAINS
+)
+
+var (
+	suffixesOnce sync.Once
+	suffixes     map[string]uint8
+)
+
+// loadSuffixes analyse la liste embarquée au premier appel.
+func loadSuffixes() {
+	suffixes = make(map[string]uint8, 10000)
+	for _, line := range strings.Split(publicSuffixData, "\n") {
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		rule, private := strings.CutSuffix(line, " private")
+		var kind uint8
+		switch {
+		case strings.HasPrefix(rule, "!"):
+			rule, kind = rule[1:], suffixException
+		case strings.HasPrefix(rule, "*."):
+			rule, kind = rule[2:], suffixWildcard
+		default:
+			kind = suffixRule
+		}
+		if private {
+			kind |= suffixPrivate
+		}
+		suffixes[rule] |= kind
+	}
+}
+
+// PublicSuffix retourne le suffixe public du nom de domaine ("co.uk" pour
+// "a.example.co.uk", "github.io" pour "x.github.io"), selon l'algorithme de
+// publicsuffix.org : une exception l'emporte, sinon la règle la plus longue, et "*" à
+// défaut. icann est false si la règle vient de la section des domaines privés ou si
+// aucune règle ne s'applique.
+func PublicSuffix(domain string) (suffix string, icann bool) {
+	suffixesOnce.Do(loadSuffixes)
+	domain = canonicalDomain(domain)
+	labels := strings.Split(domain, ".")
+	for i := range labels {
+		name := strings.Join(labels[i:], ".")
+		kind := suffixes[name]
+		if kind&suffixException != 0 {
+			return strings.Join(labels[i+1:], "."), kind&suffixPrivate == 0
+		}
+		if kind&suffixRule != 0 {
+			return name, kind&suffixPrivate == 0
+		}
+		if i+1 < len(labels) {
+			if parent := suffixes[strings.Join(labels[i+1:], ".")]; parent&suffixWildcard != 0 {
+				return name, parent&suffixPrivate == 0
+			}
+		}
+	}
+	return labels[len(labels)-1], false
+}
+
+// EffectiveTLDPlusOne retourne le domaine enregistrable : le suffixe public et le
+// label qui le précède ("example.co.uk" pour "a.example.co.uk"). Le nom est converti en
+// ASCII (punycode). Une adresse IP, un nom vide ou invalide (voir ToASCII) ou un suffixe
+// public sont des erreurs.
+func EffectiveTLDPlusOne(domain string) (string, error) {
+	if _, err := netip.ParseAddr(strings.Trim(domain, "[]")); err == nil {
+		return "", fmt.Errorf("%s is an IP address", domain)
+	}
+	if _, err := ToASCII(domain); err != nil {
+		return "", err
+	}
+	name := canonicalDomain(domain)
+	if name == "" || strings.HasPrefix(name, ".") || strings.Contains(name, "..") {
+		return "", fmt.Errorf("invalid domain %q", domain)
+	}
+	suffix, _ := PublicSuffix(name)
+	if len(name) <= len(suffix) {
+		return "", fmt.Errorf("%s is a public suffix", domain)
+	}
+	rest := name[:len(name)-len(suffix)-1]
+	return rest[strings.LastIndex(rest, ".")+1:] + "." + suffix, nil
+}
+
+// Site retourne le site de l'URL : le domaine enregistrable de son hôte, ou l'hôte
+// lui-même (ASCII, en minuscules) pour une adresse IP, "localhost" ou un suffixe public.
+// Deux URLs du même site peuvent être sur des sous-domaines différents.
+func (u *URL) Site() string {
+	name := canonicalDomain(u.Hostname())
+	if site, err := EffectiveTLDPlusOne(name); err == nil {
+		return site
+	}
+	return name
+}
+
+// canonicalDomain ramène un nom de domaine en ASCII, en minuscules, sans point final.
+func canonicalDomain(domain string) string {
+	if ascii, err := ToASCII(domain); err == nil {
+		domain = ascii
+	}
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
+}
@@ -0,0 +1,58 @@
+package neturl
+
+import "testing"
+
+func TestPublicSuffix(t *testing.T) {
+	tests := []struct {
+		domain, suffix string
+		icann          bool
+	}{
+		{"a.example.co.uk", "co.uk", true},
+		{"example.com", "com", true},
+		{"x.github.io", "github.io", false},
+		{"a.b.example.ck", "example.ck", true}, // *.ck
+		{"www.ck", "ck", true},                 // !www.ck
+		{"city.kawasaki.jp", "kawasaki.jp", true},
+		{"foo.example.unknowntld", "unknowntld", false},
+	}
+	for _, tt := range tests {
+		if suffix, icann := PublicSuffix(tt.domain); suffix != tt.suffix || icann != tt.icann {
+			t.Errorf("PublicSuffix(%q) = %q, %v, want %q, %v", tt.domain, suffix, icann, tt.suffix, tt.icann)
+		}
+	}
+}
+
+func TestEffectiveTLDPlusOne(t *testing.T) {
+	tests := []struct{ domain, want string }{
+		{"a.example.co.uk", "example.co.uk"},
+		{"b.example.co.uk", "example.co.uk"},
+		{"x.github.io", "x.github.io"},
+		{"WWW.Example.COM.", "example.com"},
+		{"shop.müller.de", "xn--mller-kva.de"},
+		{"a.b.example.ck", "b.example.ck"},
+	}
+	for _, tt := range tests {
+		if got, err := EffectiveTLDPlusOne(tt.domain); err != nil || got != tt.want {
+			t.Errorf("EffectiveTLDPlusOne(%q) = %q (%v), want %q", tt.domain, got, err, tt.want)
+		}
+	}
+	for _, domain := range []string{"co.uk", "github.io", "127.0.0.1", "::1", "", ".example.com", "-a.example.com", "xn--zz.com"} {
+		if got, err := EffectiveTLDPlusOne(domain); err == nil {
+			t.Errorf("EffectiveTLDPlusOne(%q) = %q, expected an error", domain, got)
+		}
+	}
+
+	sites := map[string]string{
+		"https://a.example.co.uk/":  "example.co.uk",
+		"https://y.github.io/page":  "y.github.io",
+		"http://localhost:8080/":    "localhost",
+		"http://[::1]/":             "::1",
+		"https://xn--mller-kva.de/": "xn--mller-kva.de",
+	}
+	for raw, want := range sites {
+		u, _ := Parse(raw)
+		if got := u.Site(); got != want {
+			t.Errorf("Site(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
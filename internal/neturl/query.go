@@ -0,0 +1,107 @@
+package neturl
+
+import (
+	"sort"
+	"strings"
+)
+
+// Values associe des clés à des listes de valeurs (paramètres de requête ou champs de formulaire).
+type Values map[string][]string
+
+// Get retourne la première valeur associée à la clé, ou une chaîne vide.
+func (v Values) Get(key string) string {
+	if vs := v[key]; len(vs) > 0 {
+		return vs[0]
+	}
+	return ""
+}
+
+// Set remplace les valeurs de la clé par une valeur unique.
+func (v Values) Set(key, value string) {
+	v[key] = []string{value}
+}
+
+// Add ajoute une valeur à la clé.
+func (v Values) Add(key, value string) {
+	v[key] = append(v[key], value)
+}
+
+// Del supprime la clé.
+func (v Values) Del(key string) {
+	delete(v, key)
+}
+
+// Has retourne true si la clé est présente.
+func (v Values) Has(key string) bool {
+	_, ok := v[key]
+	return ok
+}
+
+// Encode encode les valeurs au format application/x-www-form-urlencoded, triées par clé.
+func (v Values) Encode() string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		for _, val := range v[k] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(QueryEscape(k))
+			b.WriteByte('=')
+			b.WriteString(QueryEscape(val))
+		}
+	}
+	return b.String()
+}
+
+// QueryEscape encode une chaîne pour l'utiliser comme clé ou valeur de requête (espace → '+').
+func QueryEscape(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isUnreserved(c):
+			b.WriteByte(c)
+		case c == ' ':
+			b.WriteByte('+')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(upperHex[c>>4])
+			b.WriteByte(upperHex[c&15])
+		}
+	}
+	return b.String()
+}
+
+// QueryUnescape décode une clé ou une valeur de requête ('+' → espace).
+func QueryUnescape(s string) (string, error) {
+	return Unescape(strings.ReplaceAll(s, "+", " "))
+}
+
+// ParseQuery analyse une requête au format application/x-www-form-urlencoded. Les paires
+// mal encodées sont ignorées ; la première erreur rencontrée est retournée avec les autres valeurs.
+func ParseQuery(query string) (Values, error) {
+	v := Values{}
+	var firstErr error
+	for _, pair := range strings.FieldsFunc(query, func(r rune) bool { return r == '&' }) {
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := QueryUnescape(rawKey)
+		if err == nil {
+			var value string
+			if value, err = QueryUnescape(rawValue); err == nil {
+				v.Add(key, value)
+				continue
+			}
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return v, firstErr
+}
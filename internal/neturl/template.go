@@ -0,0 +1,339 @@
+package neturl
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"webextractor/internal/strconv"
+)
+
+// MaxTemplateURLs borne le nombre d'URLs produites par un modèle.
+const MaxTemplateURLs = 100000
+
+// Template est un modèle d'URL dont les marqueurs {…} prennent plusieurs valeurs :
+//
+//	{1..20}, {01..10}, {0..100..10}  plage d'entiers (largeur des zéros initiaux conservée)
+//	{a,b,c}                          liste de valeurs
+//	{@termes.txt}                    valeurs lues dans un fichier, une par ligne
+//	{page:1..20}                     marqueur nommé
+//	{term}                           paramètre défini à part (voir ParseTemplate)
+//
+// Les marqueurs sans nom sont désignés par leur position ("1", "2"...). Un même nom
+// utilisé plusieurs fois prend la même valeur.
+type Template struct {
+	raw    string
+	parts  []templatePart
+	params []*templateParam // dans l'ordre d'apparition
+}
+
+// templatePart est un texte littéral du modèle, ou un marqueur.
+type templatePart struct {
+	literal   string
+	param     *templateParam
+	component urlComponent // partie de l'URL où le marqueur est placé
+}
+
+// templateParam est un paramètre du modèle et ses valeurs.
+type templateParam struct {
+	name   string
+	values []string
+}
+
+// urlComponent est la partie de l'URL qui contient un marqueur, et en règle l'encodage.
+type urlComponent int
+
+const (
+	componentHost urlComponent = iota
+	componentPath
+	componentQuery
+	componentFragment
+)
+
+// TemplateURL est une URL produite par un modèle, avec les valeurs de ses paramètres.
+type TemplateURL struct {
+	URL    string
+	Params map[string]string
+}
+
+// IsTemplate retourne true si la chaîne contient un marqueur de modèle.
+func IsTemplate(s string) bool {
+	open := strings.IndexByte(s, '{')
+	return open >= 0 && strings.IndexByte(s[open:], '}') > 0
+}
+
+// ParseTemplate analyse un modèle d'URL. params définit les valeurs des marqueurs {nom},
+// avec la même syntaxe qu'à l'intérieur d'un marqueur ("1..20", "a,b", "@fichier").
+func ParseTemplate(raw string, params map[string]string) (*Template, error) {
+	t := &Template{raw: raw}
+	byName := map[string]*templateParam{}
+	used := map[string]bool{}
+	component := componentPath
+	if strings.Contains(raw, "://") {
+		component = componentHost
+	}
+
+	rest := raw
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		literal := rest[:open]
+		if strings.IndexByte(literal, '}') >= 0 {
+			return nil, fmt.Errorf("invalid URL template %q: unexpected '}'", raw)
+		}
+		component = nextComponent(component, literal)
+		t.parts = append(t.parts, templatePart{literal: literal})
+
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid URL template %q: unclosed '{'", raw)
+		}
+		expr := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+
+		name, spec, named := strings.Cut(expr, ":")
+		switch {
+		case !named && isParamName(expr):
+			// Paramètre défini à part
+			name = expr
+			var ok bool
+			if spec, ok = params[name]; !ok && byName[name] == nil {
+				return nil, fmt.Errorf("undefined URL template parameter %q", name)
+			}
+		case !named:
+			name, spec = fmt.Sprint(len(t.params)+1), expr
+		case !isParamName(name):
+			return nil, fmt.Errorf("invalid URL template parameter name %q", name)
+		}
+		used[name] = true
+
+		param := byName[name]
+		if param == nil {
+			values, err := parseTemplateValues(spec)
+			if err != nil {
+				return nil, fmt.Errorf("URL template parameter %q: %w", name, err)
+			}
+			param = &templateParam{name: name, values: values}
+			byName[name] = param
+			t.params = append(t.params, param)
+		} else if named || !isParamName(expr) {
+			return nil, fmt.Errorf("URL template parameter %q is defined twice", name)
+		}
+		t.parts = append(t.parts, templatePart{param: param, component: component})
+	}
+
+	if len(t.params) == 0 {
+		return nil, fmt.Errorf("invalid URL template %q: no parameter", raw)
+	}
+	for name := range params {
+		if !used[name] {
+			return nil, fmt.Errorf("URL template parameter %q is not used in %s", name, raw)
+		}
+	}
+	if n := t.Len(); n > MaxTemplateURLs {
+		return nil, fmt.Errorf("URL template expands to %d URLs (at most %d)", n, MaxTemplateURLs)
+	}
+	return t, nil
+}
+
+// nextComponent retourne la partie de l'URL atteinte après le texte littéral.
+func nextComponent(c urlComponent, literal string) urlComponent {
+	if c == componentHost {
+		if i := strings.Index(literal, "://"); i >= 0 {
+			literal = literal[i+3:]
+		}
+		if i := strings.IndexAny(literal, "/?#"); i >= 0 {
+			c, literal = componentPath, literal[i:]
+		}
+	}
+	if c <= componentPath && strings.ContainsAny(literal, "?") {
+		c = componentQuery
+	}
+	if strings.ContainsAny(literal, "#") {
+		c = componentFragment
+	}
+	return c
+}
+
+// isParamName retourne true pour un nom de paramètre : lettres, chiffres, '_' et '-'.
+func isParamName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// isRange retourne true si la spécification est une plage ("1..20").
+func isRange(spec string) bool {
+	return strings.Contains(spec, "..")
+}
+
+// parseTemplateValues lit les valeurs d'un marqueur : plage, fichier ou liste.
+func parseTemplateValues(spec string) ([]string, error) {
+	switch {
+	case strings.HasPrefix(spec, "@"):
+		return readTemplateValues(spec[1:])
+	case isRange(spec) && !strings.Contains(spec, ","):
+		return parseRange(spec)
+	case spec == "":
+		return nil, fmt.Errorf("no value")
+	}
+	return strings.Split(spec, ","), nil
+}
+
+// parseRange développe une plage "début..fin" ou "début..fin..pas", croissante ou
+// décroissante. Une borne écrite avec des zéros initiaux fixe la largeur des valeurs.
+func parseRange(spec string) ([]string, error) {
+	bounds := strings.Split(spec, "..")
+	if len(bounds) > 3 {
+		return nil, fmt.Errorf("invalid range %q", spec)
+	}
+	var nums [3]int
+	nums[2] = 1
+	for i, b := range bounds {
+		n, err := strconv.Atoi(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q", spec)
+		}
+		nums[i] = n
+	}
+	start, end, step := nums[0], nums[1], nums[2]
+	if step <= 0 {
+		return nil, fmt.Errorf("invalid range %q: step must be positive", spec)
+	}
+	if end < start {
+		step = -step
+	}
+	if count := (end-start)/step + 1; count > MaxTemplateURLs {
+		return nil, fmt.Errorf("range %q has %d values (at most %d)", spec, count, MaxTemplateURLs)
+	}
+
+	width := 0
+	for _, b := range bounds[:2] {
+		digits := strings.TrimPrefix(b, "-")
+		if len(digits) > 1 && digits[0] == '0' && len(b) > width {
+			width = len(b)
+		}
+	}
+	var values []string
+	for n := start; (step > 0 && n <= end) || (step < 0 && n >= end); n += step {
+		values = append(values, fmt.Sprintf("%0*d", width, n))
+	}
+	return values, nil
+}
+
+// readTemplateValues lit un fichier de valeurs : une par ligne, sans les lignes vides
+// ni les commentaires '#'.
+func readTemplateValues(path string) ([]string, error) {
+	file, err := os.Open(path) // #nosec G304 - fichier choisi par l'utilisateur
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var values []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		values = append(values, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no value in %s", path)
+	}
+	return values, nil
+}
+
+// String retourne le modèle tel qu'il a été donné.
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Params retourne les noms des paramètres, dans l'ordre d'apparition.
+func (t *Template) Params() []string {
+	names := make([]string, len(t.params))
+	for i, p := range t.params {
+		names[i] = p.name
+	}
+	return names
+}
+
+// Len retourne le nombre d'URLs produites par le modèle.
+func (t *Template) Len() int {
+	n := 1
+	for _, p := range t.params {
+		if n > MaxTemplateURLs {
+			break // évite un débordement, ParseTemplate refuse déjà le modèle
+		}
+		n *= len(p.values)
+	}
+	return n
+}
+
+// Expand produit toutes les URLs du modèle, le dernier paramètre variant le plus vite.
+// Chaque valeur est encodée selon sa place : segment de chemin, valeur de requête ou
+// fragment. Une URL produite invalide est une erreur.
+func (t *Template) Expand() ([]TemplateURL, error) {
+	urls := make([]TemplateURL, 0, t.Len())
+	index := make([]int, len(t.params))
+	for {
+		values := make(map[string]string, len(t.params))
+		for i, p := range t.params {
+			values[p.name] = p.values[index[i]]
+		}
+		var b strings.Builder
+		for _, part := range t.parts {
+			if part.param == nil {
+				b.WriteString(part.literal)
+				continue
+			}
+			b.WriteString(escapeTemplateValue(values[part.param.name], part.component))
+		}
+		u, err := Parse(b.String())
+		if err != nil {
+			return nil, fmt.Errorf("URL template %s: %w", t.raw, err)
+		}
+		urls = append(urls, TemplateURL{URL: u.String(), Params: values})
+
+		// Paramètre suivant, comme un compteur
+		i := len(index) - 1
+		for ; i >= 0; i-- {
+			index[i]++
+			if index[i] < len(t.params[i].values) {
+				break
+			}
+			index[i] = 0
+		}
+		if i < 0 {
+			return urls, nil
+		}
+	}
+}
+
+// escapeTemplateValue encode une valeur pour la partie de l'URL qui la reçoit.
+func escapeTemplateValue(value string, c urlComponent) string {
+	switch c {
+	case componentHost:
+		return value
+	case componentQuery:
+		return QueryEscape(value)
+	case componentFragment:
+		return EscapePath(value)
+	}
+	return PathEscape(value)
+}
@@ -0,0 +1,86 @@
+package neturl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateExpand(t *testing.T) {
+	terms := filepath.Join(t.TempDir(), "terms.txt")
+	os.WriteFile(terms, []byte("café crème\n\n# commentaire\nAC/DC & co\n"), 0o644)
+
+	tmpl, err := ParseTemplate("https://site.test/search/{term}?q={term}&page={1..2}", map[string]string{"term": "@" + terms})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if strings.Join(tmpl.Params(), ",") != "term,2" || tmpl.Len() != 4 {
+		t.Fatalf("unexpected parameters %v (%d URLs)", tmpl.Params(), tmpl.Len())
+	}
+	urls, err := tmpl.Expand()
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	want := []string{
+		"https://site.test/search/caf%C3%A9%20cr%C3%A8me?q=caf%C3%A9+cr%C3%A8me&page=1",
+		"https://site.test/search/caf%C3%A9%20cr%C3%A8me?q=caf%C3%A9+cr%C3%A8me&page=2",
+		"https://site.test/search/AC%2FDC%20&%20co?q=AC%2FDC+%26+co&page=1",
+		"https://site.test/search/AC%2FDC%20&%20co?q=AC%2FDC+%26+co&page=2",
+	}
+	for i, u := range urls {
+		if u.URL != want[i] {
+			t.Errorf("URL %d: got %s, want %s", i, u.URL, want[i])
+		}
+	}
+	if urls[3].Params["term"] != "AC/DC & co" || urls[3].Params["2"] != "2" {
+		t.Errorf("unexpected parameter values %v", urls[3].Params)
+	}
+}
+
+func TestTemplateValues(t *testing.T) {
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"https://{en,fr}.site.test/", []string{"https://en.site.test/", "https://fr.site.test/"}},
+		{"https://site.test/p/{08..10}", []string{"https://site.test/p/08", "https://site.test/p/09", "https://site.test/p/10"}},
+		{"https://site.test/?o={0..20..10}", []string{"https://site.test/?o=0", "https://site.test/?o=10", "https://site.test/?o=20"}},
+		{"https://site.test/?o={3..1}", []string{"https://site.test/?o=3", "https://site.test/?o=2", "https://site.test/?o=1"}},
+		{"https://site.test/{p:a,b}/{p}", []string{"https://site.test/a/a", "https://site.test/b/b"}},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseTemplate(tt.template, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.template, err)
+			continue
+		}
+		urls, _ := tmpl.Expand()
+		var got []string
+		for _, u := range urls {
+			got = append(got, u.URL)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: got %v, want %v", tt.template, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{
+		"https://site.test/{term}",              // paramètre non défini
+		"https://site.test/{1..",                // marqueur non fermé
+		"https://site.test/{1..5..0}",           // pas nul
+		"https://site.test/{p:a}/{p:b}",         // défini deux fois
+		"https://site.test/{@missing.txt}",      // fichier absent
+		"https://site.test/{1..1000}/{1..1000}", // trop d'URLs
+	} {
+		if _, err := ParseTemplate(bad, nil); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+	if _, err := ParseTemplate("https://site.test/{1..2}", map[string]string{"unused": "a"}); err == nil {
+		t.Error("expected an error for an unused parameter")
+	}
+	if IsTemplate("https://site.test/a") || !IsTemplate("https://site.test/{1..2}") {
+		t.Error("IsTemplate: unexpected result")
+	}
+}
@@ -2,84 +2,261 @@ package neturl
 
 import (
 	"errors"
+	"fmt"
+	"net/netip"
 	"strings"
 )
 
-// URL représente une URL analysée
+// URL représente une référence d'URI analysée selon la RFC 3986.
+// Les composants sont conservés sous leur forme encodée, sauf le schéma mis en minuscules.
 type URL struct {
 	Scheme   string
-	Host     string
+	User     string // userinfo brut ("user:password"), sans le '@'
+	Host     string // hôte et port éventuel : "example.com:8080", "[::1]:443"
 	Path     string
 	RawQuery string
 	Fragment string
+
+	authority bool // la référence contient une autorité ("//"), éventuellement vide (file:///)
 }
 
-// Parse analyse une chaîne d'URL brute et retourne une structure URL
-func Parse(rawurl string) (*URL, error) {
-	if rawurl == "" {
-		return nil, errors.New("empty url")
+// ParseReference analyse une référence d'URI, absolue ("https://example.com/a") ou relative
+// ("../a?b", "//cdn.example.com/x", "#top"). Les caractères interdits par la RFC 3986
+// (espaces, caractères non ASCII...) sont encodés dans le chemin, la requête et le fragment ;
+// une séquence %XX invalide, un hôte ou un port invalide sont des erreurs.
+func ParseReference(rawref string) (*URL, error) {
+	u := &URL{}
+	rest := rawref
+
+	// On retire le fragment en premier, puis la requête
+	if idx := strings.IndexByte(rest, '#'); idx >= 0 {
+		u.Fragment = rest[idx+1:]
+		rest = rest[:idx]
+	}
+	if idx := strings.IndexByte(rest, '?'); idx >= 0 {
+		u.RawQuery = rest[idx+1:]
+		rest = rest[:idx]
 	}
 
-	u := &URL{}
+	// Le schéma précède le premier ':' s'il n'est précédé ni de '/' ni d'un caractère invalide
+	if hasScheme(rest) {
+		idx := strings.IndexByte(rest, ':')
+		u.Scheme = strings.ToLower(rest[:idx])
+		rest = rest[idx+1:]
+	}
 
-	// On retire le fragment en premier
-	if idx := strings.Index(rawurl, "#"); idx >= 0 {
-		u.Fragment = rawurl[idx+1:]
-		rawurl = rawurl[:idx]
+	// L'autorité suit "//" et s'arrête au premier '/'
+	if strings.HasPrefix(rest, "//") {
+		u.authority = true
+		rest = rest[2:]
+		authority := rest
+		if idx := strings.IndexByte(rest, '/'); idx >= 0 {
+			authority, rest = rest[:idx], rest[idx:]
+		} else {
+			rest = ""
+		}
+		if idx := strings.LastIndexByte(authority, '@'); idx >= 0 {
+			u.User, authority = authority[:idx], authority[idx+1:]
+		}
+		if err := validateHost(authority); err != nil {
+			return nil, err
+		}
+		u.Host = authority
 	}
+	u.Path = rest
 
-	// On extrait la chaîne de requête
-	if idx := strings.Index(rawurl, "?"); idx >= 0 {
-		u.RawQuery = rawurl[idx+1:]
-		rawurl = rawurl[:idx]
+	var err error
+	if u.User, err = escapeInvalid(u.User, "userinfo", ":"); err != nil {
+		return nil, err
+	}
+	if u.Path, err = escapeInvalid(u.Path, "path", ":@/"); err != nil {
+		return nil, err
+	}
+	if u.RawQuery, err = escapeInvalid(u.RawQuery, "query", ":@/?"); err != nil {
+		return nil, err
 	}
+	if u.Fragment, err = escapeInvalid(u.Fragment, "fragment", ":@/?"); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
 
-	// On extrait le schéma
-	if idx := strings.Index(rawurl, "://"); idx >= 0 {
-		u.Scheme = strings.ToLower(rawurl[:idx])
-		rawurl = rawurl[idx+3:]
-	} else {
+// Parse analyse une URL absolue. Le schéma est obligatoire, et l'hôte aussi en HTTP(S),
+// dont le chemin vide devient "/".
+func Parse(rawurl string) (*URL, error) {
+	if rawurl == "" {
+		return nil, errors.New("empty url")
+	}
+	u, err := ParseReference(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" {
 		return nil, errors.New("missing protocol scheme")
 	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		if u.Host == "" {
+			return nil, errors.New("empty host")
+		}
+		if u.Path == "" {
+			u.Path = "/"
+		}
+	}
+	return u, nil
+}
 
-	// On sépare le host et le chemin
-	if idx := strings.Index(rawurl, "/"); idx >= 0 {
-		u.Host = rawurl[:idx]
-		u.Path = rawurl[idx:]
-	} else {
-		u.Host = rawurl
-		u.Path = "/"
+// validateHost vérifie la syntaxe d'un hôte avec son port éventuel : nom, IPv4,
+// littéral IPv6 ou IPvFuture entre crochets.
+func validateHost(host string) error {
+	name, port := host, ""
+	if strings.HasPrefix(host, "[") {
+		end := strings.IndexByte(host, ']')
+		if end < 0 {
+			return fmt.Errorf("invalid host %q: missing ']'", host)
+		}
+		literal := host[1:end]
+		if strings.HasPrefix(literal, "v") || strings.HasPrefix(literal, "V") {
+			if !strings.Contains(literal, ".") {
+				return fmt.Errorf("invalid IP literal %q", literal)
+			}
+		} else if addr, err := netip.ParseAddr(literal); err != nil || !addr.Is6() {
+			return fmt.Errorf("invalid IPv6 address %q", literal)
+		}
+		name, port = "", host[end+1:]
+		if port != "" && !strings.HasPrefix(port, ":") {
+			return fmt.Errorf("invalid host %q", host)
+		}
+		port = strings.TrimPrefix(port, ":")
+	} else if idx := strings.LastIndexByte(host, ':'); idx >= 0 {
+		name, port = host[:idx], host[idx+1:]
 	}
 
-	// On valide le schéma
-	if u.Scheme != "http" && u.Scheme != "https" {
-		return nil, errors.New("unsupported protocol scheme")
+	for i := 0; i < len(port); i++ {
+		if port[i] < '0' || port[i] > '9' {
+			return fmt.Errorf("invalid port %q", port)
+		}
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '%':
+			if i+2 >= len(name) || !isHex(name[i+1]) || !isHex(name[i+2]) {
+				return fmt.Errorf("invalid percent-encoding in host %q", host)
+			}
+		case isUnreserved(c) || isSubDelim(c) || c >= 0x80:
+			// Les noms internationalisés sont acceptés tels quels
+		default:
+			return fmt.Errorf("invalid character %q in host %q", c, host)
+		}
 	}
+	return nil
+}
 
-	// On valide le host
-	if u.Host == "" {
-		return nil, errors.New("empty host")
+// escapeInvalid vérifie les séquences %XX d'un composant et encode les caractères
+// qu'il ne peut pas contenir ; allowed liste les délimiteurs permis en plus des
+// caractères non réservés et des sous-délimiteurs.
+func escapeInvalid(s, component, allowed string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return "", fmt.Errorf("invalid percent-encoding in %s %q", component, s)
+			}
+		case isUnreserved(c) || isSubDelim(c) || strings.IndexByte(allowed, c) >= 0:
+		default:
+			if b.Len() == 0 {
+				b.Grow(len(s) + 8)
+				b.WriteString(s[:i])
+			}
+			b.WriteByte('%')
+			b.WriteByte(upperHex[c>>4])
+			b.WriteByte(upperHex[c&15])
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(c)
+		}
+	}
+	if b.Len() == 0 {
+		return s, nil
 	}
+	return b.String(), nil
+}
 
-	return u, nil
+// IsAbs retourne true si l'URL a un schéma.
+func (u *URL) IsAbs() bool {
+	return u.Scheme != ""
 }
 
-// String reassemble l'URL en chaîne de caractères.
+// hasAuthority retourne true si l'URL s'écrit avec "//".
+func (u *URL) hasAuthority() bool {
+	return u.authority || u.Host != "" || u.User != ""
+}
+
+// Hostname retourne l'hôte sans port ni crochets ("::1" pour "[::1]:8080").
+func (u *URL) Hostname() string {
+	host, _ := splitHostPort(u.Host)
+	return host
+}
+
+// Port retourne le port explicite de l'URL, ou une chaîne vide.
+func (u *URL) Port() string {
+	_, port := splitHostPort(u.Host)
+	return port
+}
+
+// splitHostPort sépare l'hôte et le port, en retirant les crochets d'un littéral IPv6.
+func splitHostPort(host string) (string, string) {
+	if strings.HasPrefix(host, "[") {
+		end := strings.IndexByte(host, ']')
+		if end < 0 {
+			return host, ""
+		}
+		return host[1:end], strings.TrimPrefix(host[end+1:], ":")
+	}
+	if idx := strings.LastIndexByte(host, ':'); idx >= 0 {
+		return host[:idx], host[idx+1:]
+	}
+	return host, ""
+}
+
+// Query analyse la requête de l'URL. Les paramètres mal encodés sont ignorés.
+func (u *URL) Query() Values {
+	v, _ := ParseQuery(u.RawQuery)
+	return v
+}
+
+// SetQuery remplace la requête de l'URL par les valeurs encodées.
+func (u *URL) SetQuery(v Values) {
+	u.RawQuery = v.Encode()
+}
+
+// String réassemble l'URL en chaîne de caractères (RFC 3986, section 5.3).
 func (u *URL) String() string {
 	var result strings.Builder
 
 	if u.Scheme != "" {
 		result.WriteString(u.Scheme)
-		result.WriteString("://")
+		result.WriteString(":")
 	}
 
-	result.WriteString(u.Host)
-
-	if u.Path == "" {
-		result.WriteString("/")
-	} else {
-		result.WriteString(u.Path)
+	if u.hasAuthority() {
+		result.WriteString("//")
+		if u.User != "" {
+			result.WriteString(u.User)
+			result.WriteString("@")
+		}
+		result.WriteString(u.Host)
+		if u.Path != "" && !strings.HasPrefix(u.Path, "/") {
+			result.WriteString("/")
+		}
+	} else if strings.HasPrefix(u.Path, "//") {
+		// Sans autorité, un chemin commençant par "//" serait lu comme un hôte
+		result.WriteString("/.")
 	}
+	result.WriteString(u.Path)
 
 	if u.RawQuery != "" {
 		result.WriteString("?")
@@ -94,91 +271,134 @@ func (u *URL) String() string {
 	return result.String()
 }
 
-// ResolveReference résout une référence URI en URI absolue depuis une URI de base absolue.
+// ResolveReference résout une référence par rapport à l'URL de base selon l'algorithme
+// de la RFC 3986 (section 5.2.2).
 func (u *URL) ResolveReference(ref *URL) *URL {
 	if ref == nil {
 		return u
 	}
 
-	// Si ref a un schéma, c'est absolu
-	if ref.Scheme != "" {
-		return ref
-	}
-
-	result := &URL{
-		Scheme: u.Scheme,
-		Host:   u.Host,
-	}
-
-	// On gère le chemin absolu
-	if strings.HasPrefix(ref.Path, "/") {
-		result.Path = ref.Path
+	result := &URL{Fragment: ref.Fragment}
+	switch {
+	case ref.Scheme != "":
+		result.Scheme = ref.Scheme
+		result.setAuthority(ref)
+		result.Path = removeDotSegments(ref.Path)
 		result.RawQuery = ref.RawQuery
-		result.Fragment = ref.Fragment
 		return result
-	}
 
-	// On gère le chemin relatif
-	if ref.Path == "" {
+	case ref.hasAuthority():
+		// Référence réseau (//hôte/chemin) : seul le schéma est hérité
+		result.setAuthority(ref)
+		result.Path = removeDotSegments(ref.Path)
+		result.RawQuery = ref.RawQuery
+
+	case ref.Path == "":
+		result.setAuthority(u)
 		result.Path = u.Path
+		result.RawQuery = u.RawQuery
 		if ref.RawQuery != "" {
 			result.RawQuery = ref.RawQuery
-		} else {
-			result.RawQuery = u.RawQuery
 		}
-		result.Fragment = ref.Fragment
-		return result
-	}
 
-	// On résout le chemin relatif
-	basePath := u.Path
-	if !strings.HasSuffix(basePath, "/") {
-		// Supprime le nom de fichier du chemin de base
-		if idx := strings.LastIndex(basePath, "/"); idx >= 0 {
-			basePath = basePath[:idx+1]
+	default:
+		result.setAuthority(u)
+		if strings.HasPrefix(ref.Path, "/") {
+			result.Path = removeDotSegments(ref.Path)
 		} else {
-			basePath = "/"
+			result.Path = removeDotSegments(u.merge(ref.Path))
 		}
+		result.RawQuery = ref.RawQuery
 	}
-
-	result.Path = basePath + ref.Path
-	result.RawQuery = ref.RawQuery
-	result.Fragment = ref.Fragment
-
-	// On nettoie le chemin (on retire . et ..)
-	result.Path = cleanPath(result.Path)
-
+	result.Scheme = u.Scheme
 	return result
 }
 
-// cleanPath nettoie les composants de chemin relatifs
-func cleanPath(path string) string {
-	if path == "" {
-		return "/"
+// setAuthority copie l'autorité de src.
+func (u *URL) setAuthority(src *URL) {
+	u.User, u.Host, u.authority = src.User, src.Host, src.authority
+}
+
+// merge fusionne un chemin relatif avec le chemin de base (RFC 3986, section 5.2.3).
+func (u *URL) merge(path string) string {
+	if u.hasAuthority() && u.Path == "" {
+		return "/" + path
 	}
+	if idx := strings.LastIndexByte(u.Path, '/'); idx >= 0 {
+		return u.Path[:idx+1] + path
+	}
+	return path
+}
 
-	parts := strings.Split(path, "/")
-	var clean []string
+// Resolve analyse une référence absolue ou relative (href, action de formulaire...)
+// et la résout par rapport à l'URL de base.
+func (u *URL) Resolve(ref string) (*URL, error) {
+	r, err := ParseReference(strings.TrimSpace(ref))
+	if err != nil {
+		return nil, err
+	}
+	return u.ResolveReference(r), nil
+}
 
-	for _, part := range parts {
-		switch part {
-		case "", ".":
-			// On ignore le chemin vide et le répertoire courant
-			continue
-		case "..":
-			// On supprime le répertoire parent
-			if len(clean) > 0 {
-				clean = clean[:len(clean)-1]
+// hasScheme retourne true si la chaîne commence par un schéma ("mailto:", "https:"...).
+func hasScheme(ref string) bool {
+	for i := 0; i < len(ref); i++ {
+		c := ref[i]
+		switch {
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' || c == '+' || c == '-' || c == '.':
+			if i == 0 {
+				return false
 			}
+		case c == ':':
+			return i > 0
 		default:
-			clean = append(clean, part)
+			return false
 		}
 	}
+	return false
+}
 
-	result := "/" + strings.Join(clean, "/")
-	if strings.HasSuffix(path, "/") && !strings.HasSuffix(result, "/") {
-		result += "/"
+// removeDotSegments retire les segments "." et ".." d'un chemin (RFC 3986, section 5.2.4).
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
 	}
-
-	return result
+	in := path
+	var out []string // segments de sortie, chacun avec son '/' initial éventuel
+	for in != "" {
+		switch {
+		case strings.HasPrefix(in, "../"):
+			in = in[3:]
+		case strings.HasPrefix(in, "./"):
+			in = in[2:]
+		case strings.HasPrefix(in, "/./"):
+			in = in[2:]
+		case in == "/.":
+			in = "/"
+		case strings.HasPrefix(in, "/../"):
+			in = in[3:]
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case in == "/..":
+			in = "/"
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case in == "." || in == "..":
+			in = ""
+		default:
+			// Le premier segment, avec son '/' initial, passe en sortie
+			end := strings.IndexByte(in[1:], '/')
+			if end < 0 {
+				out = append(out, in)
+				in = ""
+			} else {
+				out = append(out, in[:end+1])
+				in = in[end+1:]
+			}
+		}
+	}
+	return strings.Join(out, "")
 }
@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"strings"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/neturl"
+)
+
+// Form représente un formulaire HTML avec ses champs et leurs valeurs par défaut.
+type Form struct {
+	Action  string // attribut action brut, vide pour la page courante
+	Method  string // GET ou POST, en majuscules
+	Enctype string // application/x-www-form-urlencoded par défaut
+	Fields  []FormField
+	Node    *htmlparser.Node
+}
+
+// FormField représente un champ nommé d'un formulaire.
+type FormField struct {
+	Name  string
+	Type  string // text, hidden, password, checkbox, file, select, textarea...
+	Value string
+}
+
+// FindForms parcourt l'arbre HTML et extrait tous les formulaires.
+func FindForms(root *htmlparser.Node) []Form {
+	var forms []Form
+	for _, n := range FindAll(root, "form") {
+		forms = append(forms, ParseForm(n))
+	}
+	return forms
+}
+
+// ParseForm lit les attributs et les champs d'un élément <form>.
+// Seuls les champs qui seraient envoyés par un navigateur sont retenus :
+// les cases non cochées et les boutons sont ignorés.
+func ParseForm(n *htmlparser.Node) Form {
+	form := Form{
+		Action:  attr(n, "action"),
+		Method:  strings.ToUpper(strings.TrimSpace(attr(n, "method"))),
+		Enctype: strings.ToLower(strings.TrimSpace(attr(n, "enctype"))),
+		Node:    n,
+	}
+	if form.Method != "POST" {
+		form.Method = "GET"
+	}
+	if form.Enctype == "" {
+		form.Enctype = "application/x-www-form-urlencoded"
+	}
+
+	var rec func(*htmlparser.Node)
+	rec = func(c *htmlparser.Node) {
+		if c.Type == htmlparser.ElementNode {
+			if field, ok := parseField(c); ok {
+				form.Fields = append(form.Fields, field)
+			}
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			rec(child)
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		rec(c)
+	}
+
+	return form
+}
+
+// HasField retourne true si le formulaire contient un champ portant ce nom.
+func (f Form) HasField(name string) bool {
+	for _, field := range f.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Values retourne les valeurs par défaut du formulaire (les champs fichier sont exclus).
+func (f Form) Values() neturl.Values {
+	values := neturl.Values{}
+	for _, field := range f.Fields {
+		if field.Type == "file" {
+			continue
+		}
+		values.Add(field.Name, field.Value)
+	}
+	return values
+}
+
+// parseField extrait un champ d'un élément input, select ou textarea.
+func parseField(n *htmlparser.Node) (FormField, bool) {
+	name := attr(n, "name")
+	if name == "" || hasAttr(n, "disabled") {
+		return FormField{}, false
+	}
+
+	switch n.Data {
+	case "input":
+		fieldType := strings.ToLower(attr(n, "type"))
+		if fieldType == "" {
+			fieldType = "text"
+		}
+		switch fieldType {
+		case "submit", "button", "image", "reset":
+			return FormField{}, false
+		case "checkbox", "radio":
+			if !hasAttr(n, "checked") {
+				return FormField{}, false
+			}
+			value := attr(n, "value")
+			if !hasAttr(n, "value") {
+				value = "on"
+			}
+			return FormField{Name: name, Type: fieldType, Value: value}, true
+		}
+		return FormField{Name: name, Type: fieldType, Value: attr(n, "value")}, true
+
+	case "textarea":
+		return FormField{Name: name, Type: "textarea", Value: TextContent(n)}, true
+
+	case "select":
+		options := FindAll(n, "option")
+		value := ""
+		for i, opt := range options {
+			if i == 0 || hasAttr(opt, "selected") {
+				value = optionValue(opt)
+			}
+			if hasAttr(opt, "selected") {
+				break
+			}
+		}
+		return FormField{Name: name, Type: "select", Value: value}, true
+	}
+
+	return FormField{}, false
+}
+
+// optionValue retourne la valeur d'une option (son texte si l'attribut value est absent).
+func optionValue(opt *htmlparser.Node) string {
+	if hasAttr(opt, "value") {
+		return attr(opt, "value")
+	}
+	return TextContent(opt)
+}
+
+// attr retourne la valeur d'un attribut, ou une chaîne vide.
+func attr(n *htmlparser.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr retourne true si l'attribut est présent.
+func hasAttr(n *htmlparser.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"strings"
+
+	"webextractor/internal/htmlparser"
+	"webextractor/internal/neturl"
+)
+
+// BaseURL retourne l'URL par rapport à laquelle les liens du document se résolvent :
+// le premier <base href> résolu par rapport à l'URL de la page, ou l'URL de la page elle-même.
+func BaseURL(root *htmlparser.Node, page *neturl.URL) *neturl.URL {
+	for _, n := range FindAll(root, "base") {
+		if !hasAttr(n, "href") {
+			continue
+		}
+		if base, err := page.Resolve(attr(n, "href")); err == nil {
+			base.Fragment = ""
+			return base
+		}
+		break
+	}
+	return page
+}
+
+// Canonical retourne la cible brute du premier <link rel="canonical">, ou "".
+func Canonical(root *htmlparser.Node) string {
+	for _, n := range FindAll(root, "link") {
+		if hasRel(n, "canonical") {
+			if href := strings.TrimSpace(attr(n, "href")); href != "" {
+				return href
+			}
+		}
+	}
+	return ""
+}
+
+// MetaRefresh retourne la cible brute d'un <meta http-equiv="refresh" content="5; url=...">.
+// Un rafraîchissement sans URL (rechargement de la page) est ignoré.
+func MetaRefresh(root *htmlparser.Node) (string, bool) {
+	for _, n := range FindAll(root, "meta") {
+		if !strings.EqualFold(strings.TrimSpace(attr(n, "http-equiv")), "refresh") {
+			continue
+		}
+		if target := refreshTarget(attr(n, "content")); target != "" {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// refreshTarget extrait l'URL de l'attribut content d'un meta refresh :
+// "0;url=/suite", "5; URL='/suite'" ou "3, /suite".
+func refreshTarget(content string) string {
+	idx := strings.IndexAny(content, ";,")
+	if idx < 0 {
+		return ""
+	}
+	rest := strings.TrimSpace(content[idx+1:])
+	if len(rest) >= 3 && strings.EqualFold(rest[:3], "url") {
+		after := strings.TrimSpace(rest[3:])
+		if strings.HasPrefix(after, "=") {
+			rest = strings.TrimSpace(after[1:])
+		}
+	}
+	if len(rest) > 0 && (rest[0] == '\'' || rest[0] == '"') {
+		quote := rest[0]
+		rest = rest[1:]
+		if end := strings.IndexByte(rest, quote); end >= 0 {
+			rest = rest[:end]
+		}
+	}
+	return strings.TrimSpace(rest)
+}
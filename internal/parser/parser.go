@@ -49,13 +49,30 @@ func Compile(selector string) MatchFunc {
 			return false
 		}
 	default:
-		tag := strings.ToLower(selector)
+		tag := selector
 		return func(n *htmlparser.Node) bool {
-			return n.Type == htmlparser.ElementNode && n.Data == tag
+			return n.Type == htmlparser.ElementNode && matchTag(n, tag)
 		}
 	}
 }
 
+// matchTag compare le nom de l'élément au sélecteur sans tenir compte de la casse.
+// Dans un document XML, un sélecteur sans préfixe correspond aussi au nom local
+// ("creator" → "dc:creator") ; en HTML, le nom doit être identique.
+func matchTag(n *htmlparser.Node, tag string) bool {
+	name := n.Data
+	if strings.EqualFold(name, tag) {
+		return true
+	}
+	if strings.Contains(tag, ":") {
+		return false
+	}
+	if idx := strings.IndexByte(name, ':'); idx >= 0 && n.InXMLDocument() {
+		return strings.EqualFold(name[idx+1:], tag)
+	}
+	return false
+}
+
 // FindAll parcourt l'arbre DOM en profondeur et retourne les nœuds qui correspondent au sélecteur.
 func FindAll(root *htmlparser.Node, selector string) []*htmlparser.Node {
 	matcher := Compile(selector)
@@ -94,6 +111,7 @@ func TextContent(n *htmlparser.Node) string {
 type Link struct {
 	Href string
 	Text string
+	Rel  string // valeur brute de l'attribut rel ("nofollow", "next"...)
 }
 
 // FindLinks parcourt l'arbre HTML et extrait tous les hyperliens.
@@ -111,6 +129,7 @@ func FindLinks(n *htmlparser.Node) []Link {
 			links = append(links, Link{
 				Href: href,
 				Text: strings.TrimSpace(TextContent(n)),
+				Rel:  strings.TrimSpace(attr(n, "rel")),
 			})
 		}
 	}
@@ -120,3 +139,53 @@ func FindLinks(n *htmlparser.Node) []Link {
 	}
 	return links
 }
+
+// NextLink retourne la cible brute du lien vers la page suivante. L'élément désigné
+// par le sélecteur est utilisé en priorité (son href, celui de son lien parent ou du
+// premier lien qu'il contient) ; à défaut, ou sans sélecteur, un <link> ou un <a>
+// portant rel="next" est utilisé. Une chaîne vide signifie qu'il n'y a pas de page suivante.
+func NextLink(root *htmlparser.Node, selector string) string {
+	if strings.TrimSpace(selector) != "" {
+		for _, n := range FindAll(root, selector) {
+			if href := linkTarget(n); href != "" {
+				return href
+			}
+		}
+	}
+
+	for _, tag := range []string{"link", "a"} {
+		for _, n := range FindAll(root, tag) {
+			if hasRel(n, "next") && strings.TrimSpace(attr(n, "href")) != "" {
+				return strings.TrimSpace(attr(n, "href"))
+			}
+		}
+	}
+	return ""
+}
+
+// linkTarget retourne le href porté par l'élément, un lien parent ou un lien descendant.
+func linkTarget(n *htmlparser.Node) string {
+	for cur := n; cur != nil; cur = cur.Parent {
+		if cur.Type == htmlparser.ElementNode && (cur.Data == "a" || cur.Data == "link") {
+			if href := strings.TrimSpace(attr(cur, "href")); href != "" {
+				return href
+			}
+		}
+	}
+	for _, a := range FindAll(n, "a") {
+		if href := strings.TrimSpace(attr(a, "href")); href != "" {
+			return href
+		}
+	}
+	return ""
+}
+
+// hasRel retourne true si l'attribut rel de l'élément contient la valeur donnée.
+func hasRel(n *htmlparser.Node, value string) bool {
+	for _, rel := range strings.Fields(attr(n, "rel")) {
+		if strings.EqualFold(rel, value) {
+			return true
+		}
+	}
+	return false
+}
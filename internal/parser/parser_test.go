@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"webextractor/internal/htmlparser"
+	"webextractor/internal/neturl"
 )
 
 const sampleHTML = `<html><body><div id="main" class="content highlight"><p>First</p><p class="note">Second</p></div><span class="note">Third</span></body></html>`
@@ -94,3 +95,193 @@ func TestFindLinks(t *testing.T) {
 		}
 	}
 }
+
+func TestFindLinksRel(t *testing.T) {
+	doc, err := htmlparser.Parse(strings.NewReader(`<html><body><a href="/a" rel="nofollow noopener">A</a><a href="/b">B</a></body></html>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	links := FindLinks(doc)
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(links))
+	}
+	if links[0].Rel != "nofollow noopener" || links[1].Rel != "" {
+		t.Errorf("Expected rel attribute to be kept, got %q and %q", links[0].Rel, links[1].Rel)
+	}
+}
+
+func TestFindAllXML(t *testing.T) {
+	feed := `<?xml version="1.0"?>
+<rss xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <item><title>One</title><pubDate>Mon</pubDate><dc:creator>Ann</dc:creator></item>
+    <item><title>Two</title><pubDate>Tue</pubDate><dc:creator>Bob</dc:creator></item>
+  </channel>
+</rss>`
+
+	doc, err := htmlparser.ParseXML(strings.NewReader(feed))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		sel   string
+		count int
+	}{
+		{"title", 2},
+		{"pubDate", 2},
+		{"pubdate", 2},
+		{"dc:creator", 2},
+		{"creator", 2},
+		{"other:creator", 0},
+	}
+	for _, tc := range tests {
+		if nodes := FindAll(doc, tc.sel); len(nodes) != tc.count {
+			t.Errorf("selector %s expected %d got %d", tc.sel, tc.count, len(nodes))
+		}
+	}
+
+	if txt := TextContent(FindAll(doc, "dc:creator")[1]); txt != "Bob" {
+		t.Errorf("expected 'Bob', got %q", txt)
+	}
+
+	// Le contenu CDATA est conservé tel quel
+	cdata, err := htmlparser.ParseXML(strings.NewReader("<rss><item><pre><![CDATA[  a\n  b ]]></pre></item></rss>"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if txt := FindAll(cdata, "pre")[0].FirstChild.Data; txt != "  a\n  b " {
+		t.Errorf("expected CDATA to be kept verbatim, got %q", txt)
+	}
+
+	// En HTML, le nom local n'est pas comparé
+	page, _ := htmlparser.Parse(strings.NewReader(`<html><body><foo:svg></foo:svg><svg></svg></body></html>`))
+	if nodes := FindAll(page, "svg"); len(nodes) != 1 {
+		t.Errorf("expected svg to match only <svg> in HTML, got %d", len(nodes))
+	}
+}
+
+func TestFindForms(t *testing.T) {
+	page := `<html><body>
+		<form method="post" action="/submit" enctype="multipart/form-data">
+			<input type="hidden" name="token" value="t1">
+			<input name="q" value="shoes">
+			<input type="checkbox" name="new" checked>
+			<input type="checkbox" name="used" value="yes">
+			<input type="radio" name="size" value="m" checked>
+			<input type="file" name="upload">
+			<input type="submit" name="go" value="Search">
+			<input name="off" value="x" disabled>
+			<select name="sort"><option value="price">Price</option><option selected>Date</option></select>
+			<textarea name="notes">Hello</textarea>
+		</form>
+		<form><input name="other"></form>
+	</body></html>`
+
+	doc, err := htmlparser.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	forms := FindForms(doc)
+	if len(forms) != 2 {
+		t.Fatalf("expected 2 forms, got %d", len(forms))
+	}
+
+	form := forms[0]
+	if form.Method != "POST" || form.Action != "/submit" || form.Enctype != "multipart/form-data" {
+		t.Errorf("unexpected form attributes: %+v", form)
+	}
+	if forms[1].Method != "GET" || forms[1].Enctype != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected defaults for second form: %+v", forms[1])
+	}
+
+	values := form.Values()
+	expected := map[string]string{
+		"token": "t1",
+		"q":     "shoes",
+		"new":   "on",
+		"size":  "m",
+		"sort":  "Date",
+		"notes": "Hello",
+	}
+	for name, want := range expected {
+		if got := values.Get(name); got != want {
+			t.Errorf("field %s: expected %q, got %q", name, want, got)
+		}
+	}
+	for _, name := range []string{"used", "upload", "go", "off"} {
+		if values.Has(name) {
+			t.Errorf("field %s should not be submitted", name)
+		}
+	}
+	if !form.HasField("upload") {
+		t.Errorf("expected file field to be listed")
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	page := `<html><head><link rel="next" href="/head-next"></head><body>
+		<ul class="pager"><li class="next"><a href="?page=2">Suivant</a></li></ul>
+		<a class="more" href="/more"><span>Plus</span></a>
+	</body></html>`
+	doc, err := htmlparser.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{".next", "?page=2"},       // lien descendant
+		{"span", "/more"},          // lien ancêtre
+		{"", "/head-next"},         // rel="next" sans sélecteur
+		{".missing", "/head-next"}, // rel="next" à défaut de correspondance
+	}
+	for _, tt := range tests {
+		if got := NextLink(doc, tt.selector); got != tt.want {
+			t.Errorf("NextLink(%q) = %q, want %q", tt.selector, got, tt.want)
+		}
+	}
+
+	last, _ := htmlparser.Parse(strings.NewReader(`<html><body><a href="/prev" rel="prev">‹</a></body></html>`))
+	if got := NextLink(last, ".next"); got != "" {
+		t.Errorf("expected no next link on the last page, got %q", got)
+	}
+}
+
+func TestDocumentHead(t *testing.T) {
+	page := `<html><head>
+		<base href="https://cdn.example.com/v2/">
+		<link rel="canonical" href="/article">
+		<meta http-equiv="refresh" content="0; url=next.html">
+	</head><body></body></html>`
+	doc, err := htmlparser.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	pageURL, _ := neturl.Parse("https://example.com/a/b")
+	if base := BaseURL(doc, pageURL); base.String() != "https://cdn.example.com/v2/" {
+		t.Errorf("unexpected base URL %s", base)
+	}
+	if got := Canonical(doc); got != "/article" {
+		t.Errorf("unexpected canonical %q", got)
+	}
+	if got, ok := MetaRefresh(doc); !ok || got != "next.html" {
+		t.Errorf("unexpected meta refresh %q", got)
+	}
+
+	for content, want := range map[string]string{
+		"5;URL='/x y'": "/x y",
+		"3, /suite":    "/suite",
+		`1; url="a"`:   "a",
+		"10":           "",
+	} {
+		if got := refreshTarget(content); got != want {
+			t.Errorf("refreshTarget(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package robots
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"webextractor/internal/strconv"
+)
+
+// Rules représente le contenu analysé d'un fichier robots.txt (RFC 9309).
+type Rules struct {
+	groups   []group
+	Sitemaps []string // URLs déclarées par les lignes "Sitemap:"
+}
+
+// group regroupe les règles qui s'appliquent à un ou plusieurs user-agents.
+type group struct {
+	agents     []string
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+// rule est une directive Allow ou Disallow.
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+// AllowAll retourne des règles qui autorisent toutes les URLs.
+func AllowAll() *Rules {
+	return &Rules{}
+}
+
+// DisallowAll retourne des règles qui interdisent toutes les URLs.
+func DisallowAll() *Rules {
+	return &Rules{
+		groups: []group{{agents: []string{"*"}, rules: []rule{{allow: false, pattern: "/"}}}},
+	}
+}
+
+// Parse analyse un fichier robots.txt. Les lignes invalides sont ignorées.
+func Parse(r io.Reader) (*Rules, error) {
+	rules := &Rules{}
+	var current *group
+	inAgentLines := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// On retire les commentaires
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Des lignes user-agent consécutives partagent le même groupe
+			if !inAgentLines || current == nil {
+				rules.groups = append(rules.groups, group{})
+				current = &rules.groups[len(rules.groups)-1]
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgentLines = true
+
+		case "allow", "disallow":
+			inAgentLines = false
+			// Une règle vide ne restreint rien
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, rule{allow: key == "allow", pattern: value})
+
+		case "crawl-delay":
+			inAgentLines = false
+			if current == nil {
+				continue
+			}
+			seconds, err := strconv.ParseFloat(value)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			current.crawlDelay = time.Duration(seconds * float64(time.Second))
+
+		case "sitemap":
+			// Les sitemaps sont indépendants des groupes
+			if value != "" {
+				rules.Sitemaps = append(rules.Sitemaps, value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Allowed indique si le chemin (avec sa requête éventuelle) est autorisé pour le user-agent.
+func (r *Rules) Allowed(userAgent, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	// Le fichier robots.txt lui-même est toujours accessible
+	if path == "/robots.txt" {
+		return true
+	}
+
+	var best *rule
+	for _, g := range r.matchingGroups(userAgent) {
+		for i := range g.rules {
+			candidate := &g.rules[i]
+			if !matchPattern(candidate.pattern, path) {
+				continue
+			}
+			// La règle la plus longue gagne, Allow l'emporte en cas d'égalité
+			if best == nil ||
+				len(candidate.pattern) > len(best.pattern) ||
+				(len(candidate.pattern) == len(best.pattern) && candidate.allow && !best.allow) {
+				best = candidate
+			}
+		}
+	}
+
+	return best == nil || best.allow
+}
+
+// CrawlDelay retourne le délai demandé entre deux requêtes pour le user-agent (0 si absent).
+func (r *Rules) CrawlDelay(userAgent string) time.Duration {
+	var delay time.Duration
+	for _, g := range r.matchingGroups(userAgent) {
+		if g.crawlDelay > delay {
+			delay = g.crawlDelay
+		}
+	}
+	return delay
+}
+
+// matchingGroups retourne les groupes du user-agent, ou ceux de "*" à défaut.
+func (r *Rules) matchingGroups(userAgent string) []*group {
+	token := productToken(userAgent)
+
+	var specific, wildcard []*group
+	for i := range r.groups {
+		g := &r.groups[i]
+		for _, agent := range g.agents {
+			if agent == "*" {
+				wildcard = append(wildcard, g)
+				break
+			}
+			if token != "" && agent == token {
+				specific = append(specific, g)
+				break
+			}
+		}
+	}
+
+	if len(specific) > 0 {
+		return specific
+	}
+	return wildcard
+}
+
+// productToken extrait le nom du produit d'un User-Agent ("WebExtractor/0.1" → "webextractor").
+func productToken(userAgent string) string {
+	token := strings.TrimSpace(userAgent)
+	if idx := strings.IndexAny(token, "/ "); idx >= 0 {
+		token = token[:idx]
+	}
+	return strings.ToLower(token)
+}
+
+// matchPattern vérifie si le chemin correspond au motif robots.txt.
+// '*' correspond à n'importe quelle séquence et '$' en fin de motif ancre la fin du chemin.
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+
+	// On découpe le motif autour des jokers
+	parts := strings.Split(pattern, "*")
+
+	// Le premier segment doit être un préfixe du chemin
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+
+	if len(parts) == 1 {
+		return !anchored || pos == len(path)
+	}
+
+	// Les segments intermédiaires sont cherchés au plus tôt
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+
+	last := parts[len(parts)-1]
+	if anchored {
+		return len(path)-pos >= len(last) && strings.HasSuffix(path, last)
+	}
+	return strings.Contains(path[pos:], last)
+}
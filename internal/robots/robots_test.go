@@ -0,0 +1,107 @@
+package robots
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleRobots = `
+# Exemple de robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search?*sort=
+Crawl-delay: 2
+
+User-agent: WebExtractor
+User-agent: OtherBot
+Disallow: /admin
+Allow: /admin/help
+Crawl-delay: 0.5
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestParseAndAllowed(t *testing.T) {
+	rules, err := Parse(strings.NewReader(sampleRobots))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		agent string
+		path  string
+		want  bool
+	}{
+		{"SomeBot/1.0", "/", true},
+		{"SomeBot/1.0", "/private/data", false},
+		{"SomeBot/1.0", "/private/public/page", true},
+		{"SomeBot/1.0", "/docs/file.pdf", false},
+		{"SomeBot/1.0", "/docs/file.pdf?x=1", true},
+		{"SomeBot/1.0", "/search?q=a&sort=asc", false},
+		{"SomeBot/1.0", "/search?q=a", true},
+		{"SomeBot/1.0", "/robots.txt", true},
+		{"WebExtractor/0.1", "/private/data", true},
+		{"WebExtractor/0.1", "/admin/users", false},
+		{"WebExtractor/0.1", "/admin/help", true},
+		{"webextractor", "/admin", false},
+	}
+
+	for _, tc := range tests {
+		if got := rules.Allowed(tc.agent, tc.path); got != tc.want {
+			t.Errorf("Allowed(%q, %q) = %v, expected %v", tc.agent, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestCrawlDelayAndSitemaps(t *testing.T) {
+	rules, err := Parse(strings.NewReader(sampleRobots))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if d := rules.CrawlDelay("WebExtractor/0.1"); d != 500*time.Millisecond {
+		t.Errorf("expected 500ms crawl delay, got %v", d)
+	}
+	if d := rules.CrawlDelay("SomeBot"); d != 2*time.Second {
+		t.Errorf("expected 2s crawl delay, got %v", d)
+	}
+	if len(rules.Sitemaps) != 1 || rules.Sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("unexpected sitemaps: %v", rules.Sitemaps)
+	}
+}
+
+func TestAllowAllDisallowAll(t *testing.T) {
+	if !AllowAll().Allowed("WebExtractor/0.1", "/anything") {
+		t.Error("AllowAll should allow every path")
+	}
+	if DisallowAll().Allowed("WebExtractor/0.1", "/anything") {
+		t.Error("DisallowAll should refuse every path")
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/", "/a", true},
+		{"/fish", "/fish.html", true},
+		{"/fish$", "/fish.html", false},
+		{"/fish$", "/fish", true},
+		{"/*.php", "/index.php?x", true},
+		{"/*.php$", "/index.php?x", false},
+		{"/a*b*c", "/a-x-b-y-c-z", true},
+		{"/a*b*c$", "/a-x-b-y-c-z", false},
+		{"/*/shop/*", "/fr/shop/item", true},
+	}
+
+	for _, tc := range tests {
+		if got := matchPattern(tc.pattern, tc.path); got != tc.want {
+			t.Errorf("matchPattern(%q, %q) = %v, expected %v", tc.pattern, tc.path, got, tc.want)
+		}
+	}
+}
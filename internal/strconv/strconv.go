@@ -52,3 +52,55 @@ func Atoi(s string) (int, error) {
 	}
 	return result, nil
 }
+
+// ParseFloat convertit une chaîne décimale simple (ex: "1", "0.5", "-2.25") en float64.
+// Les notations exponentielles ne sont pas supportées.
+func ParseFloat(s string) (float64, error) {
+	if s == "" {
+		return 0, errors.New("invalid syntax")
+	}
+
+	neg := false
+	i := 0
+
+	// On gère le signe
+	if s[0] == '-' || s[0] == '+' {
+		neg = s[0] == '-'
+		i = 1
+	}
+	if i >= len(s) {
+		return 0, errors.New("invalid syntax")
+	}
+
+	result := 0.0
+	digits := 0
+	for ; i < len(s) && s[i] != '.'; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, errors.New("invalid syntax")
+		}
+		result = result*10 + float64(s[i]-'0')
+		digits++
+	}
+
+	// On lit la partie décimale
+	if i < len(s) && s[i] == '.' {
+		i++
+		scale := 0.1
+		for ; i < len(s); i++ {
+			if s[i] < '0' || s[i] > '9' {
+				return 0, errors.New("invalid syntax")
+			}
+			result += float64(s[i]-'0') * scale
+			scale /= 10
+			digits++
+		}
+	}
+
+	if digits == 0 {
+		return 0, errors.New("invalid syntax")
+	}
+	if neg {
+		return -result, nil
+	}
+	return result, nil
+}
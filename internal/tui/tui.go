@@ -2,6 +2,7 @@ package tui
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -30,6 +31,8 @@ type PageInfo struct {
 	H3         []string
 	Paragraphs []string
 	Links      []parser.Link
+	OutOfScope map[string]string // raison par URL des liens écartés par les règles -include/-exclude
+	External   map[string]string // site (domaine enregistrable) par URL des liens vers un autre site
 	Images     []ImageInfo
 	Lists      []string
 }
@@ -59,7 +62,21 @@ type SelectionState struct {
 // PromptSelectors entre dans une session interactive où l'utilisateur peut choisir des éléments
 // individuellement et combiner les sélections entre différentes catégories.
 func PromptSelectors(root *htmlparser.Node, currentURL *neturl.URL) (TuiResult, error) {
+	return PromptSelectorsContext(context.Background(), root, currentURL)
+}
+
+// PromptSelectorsContext fonctionne comme PromptSelectors mais rend la main dès que le contexte
+// est annulé, même si l'utilisateur n'a rien saisi.
+func PromptSelectorsContext(ctx context.Context, root *htmlparser.Node, currentURL *neturl.URL) (TuiResult, error) {
+	return PromptSelectorsFiltered(ctx, root, currentURL, nil)
+}
+
+// PromptSelectorsFiltered fonctionne comme PromptSelectorsContext et signale dans la liste
+// des liens ceux que les règles de filter écartent du crawl et de la pagination.
+func PromptSelectorsFiltered(ctx context.Context, root *htmlparser.Node, currentURL *neturl.URL, filter *neturl.Filter) (TuiResult, error) {
 	pageInfo := extractPageInfo(root, currentURL)
+	markOutOfScope(&pageInfo, filter)
+	markExternal(&pageInfo, currentURL)
 	elements := buildSelectableElements(pageInfo)
 	state := SelectionState{
 		Elements: elements,
@@ -76,7 +93,10 @@ func PromptSelectors(root *htmlparser.Node, currentURL *neturl.URL) (TuiResult,
 
 		fmt.Print("\n🎯 Votre choix : ")
 
-		line, _ := reader.ReadString('\n')
+		line, err := readLine(ctx, reader)
+		if err != nil && ctx.Err() != nil {
+			return TuiResult{}, err
+		}
 		line = strings.TrimSpace(line)
 
 		switch {
@@ -95,7 +115,7 @@ func PromptSelectors(root *htmlparser.Node, currentURL *neturl.URL) (TuiResult,
 			continue
 
 		case strings.ToLower(line) == "links" || strings.ToLower(line) == "liens":
-			printAvailableLinks(pageInfo.Links)
+			printAvailableLinks(pageInfo)
 			continue
 
 		case strings.HasPrefix(strings.ToLower(line), "l"):
@@ -123,6 +143,27 @@ func PromptSelectors(root *htmlparser.Node, currentURL *neturl.URL) (TuiResult,
 	}
 }
 
+// readLine lit une ligne sur reader en s'interrompant si le contexte est annulé.
+func readLine(ctx context.Context, reader *bufio.Reader) (string, error) {
+	type lineResult struct {
+		line string
+		err  error
+	}
+
+	ch := make(chan lineResult, 1)
+	go func() {
+		line, err := reader.ReadString('\n')
+		ch <- lineResult{line: line, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-ch:
+		return res.line, res.err
+	}
+}
+
 func extractPageInfo(root *htmlparser.Node, currentURL *neturl.URL) PageInfo {
 	info := PageInfo{
 		URL: currentURL.String(),
@@ -165,12 +206,13 @@ func extractPageInfo(root *htmlparser.Node, currentURL *neturl.URL) PageInfo {
 		}
 	}
 
+	// Les liens relatifs se résolvent par rapport au <base href> s'il existe
+	base := parser.BaseURL(root, currentURL)
 	links := parser.FindLinks(root)
 	for _, link := range links {
 		if link.Href != "" {
-			parsedHref, err := neturl.Parse(link.Href)
-			if err == nil {
-				link.Href = currentURL.ResolveReference(parsedHref).String()
+			if resolved, err := base.Resolve(link.Href); err == nil {
+				link.Href = resolved.String()
 			}
 			if link.Text != "" {
 				info.Links = append(info.Links, link)
@@ -568,8 +610,41 @@ func handleFinishWithSelections(state SelectionState) (TuiResult, error) {
 	}, nil
 }
 
-// printAvailableLinks affiche uniquement les liens disponibles pour la navigation
-func printAvailableLinks(links []parser.Link) {
+// markExternal relève les liens HTTP(S) de la page vers un autre site que le sien, les
+// sous-domaines d'un même domaine enregistrable faisant partie du site.
+func markExternal(info *PageInfo, page *neturl.URL) {
+	if page == nil {
+		return
+	}
+	site := page.Site()
+	for _, link := range info.Links {
+		u, err := neturl.Parse(link.Href)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Site() == site {
+			continue
+		}
+		if info.External == nil {
+			info.External = map[string]string{}
+		}
+		info.External[link.Href] = u.Site()
+	}
+}
+
+// markOutOfScope relève les liens de la page écartés par les règles du filtre.
+func markOutOfScope(info *PageInfo, filter *neturl.Filter) {
+	for _, link := range info.Links {
+		if reason := filter.Explain(link.Href); reason != "" {
+			if info.OutOfScope == nil {
+				info.OutOfScope = map[string]string{}
+			}
+			info.OutOfScope[link.Href] = reason
+		}
+	}
+}
+
+// printAvailableLinks affiche uniquement les liens disponibles pour la navigation,
+// en signalant ceux qui sont hors des règles -include/-exclude
+func printAvailableLinks(info PageInfo) {
+	links := info.Links
 	if len(links) == 0 {
 		fmt.Printf("\n🚫 Aucun lien disponible sur cette page.\n")
 		return
@@ -585,7 +660,14 @@ func printAvailableLinks(links []parser.Link) {
 			linkText = linkText[:57] + "..."
 		}
 		fmt.Printf("[L%d] %s\n", i, linkText)
-		fmt.Printf("     → %s\n\n", link.Href)
+		fmt.Printf("     → %s\n", link.Href)
+		if site, ok := info.External[link.Href]; ok {
+			fmt.Printf("     🌍 Site externe : %s\n", site)
+		}
+		if reason, ok := info.OutOfScope[link.Href]; ok {
+			fmt.Printf("     ⛔ Hors portée : %s\n", reason)
+		}
+		fmt.Println()
 	}
 
 	fmt.Printf(strings.Repeat("═", 70) + "\n")
@@ -385,3 +385,68 @@ func TestTruncateText(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractPageInfoBaseHref(t *testing.T) {
+	htmlStr := `<html><head><base href="/docs/v1/"></head><body><a href="intro">Intro</a><a href="/">Home</a></body></html>`
+	doc, err := htmlparser.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	pageURL, _ := neturl.Parse("https://test.com/other/page")
+	info := extractPageInfo(doc, pageURL)
+	if len(info.Links) != 2 || info.Links[0].Href != "https://test.com/docs/v1/intro" || info.Links[1].Href != "https://test.com/" {
+		t.Errorf("Expected links resolved against <base href>, got %v", info.Links)
+	}
+}
+
+func TestMarkOutOfScope(t *testing.T) {
+	htmlStr := `<html><body><a href="/products/shoes">Shoes</a><a href="/cart">Cart</a></body></html>`
+	doc, err := htmlparser.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	filter, _ := neturl.NewFilter(nil, []string{"/cart*"}, neturl.DefaultRules)
+
+	pageURL, _ := neturl.Parse("https://test.com/")
+	info := extractPageInfo(doc, pageURL)
+	markOutOfScope(&info, filter)
+	if len(info.OutOfScope) != 1 || info.OutOfScope["https://test.com/cart"] != `excluded by "/cart*"` {
+		t.Errorf("Expected only the cart link out of scope, got %v", info.OutOfScope)
+	}
+}
+
+func TestMarkExternal(t *testing.T) {
+	htmlStr := `<html><body><a href="https://docs.test.co.uk/">Docs</a><a href="https://other.co.uk/">Other</a><a href="mailto:a@test.co.uk">Mail</a></body></html>`
+	doc, err := htmlparser.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	pageURL, _ := neturl.Parse("https://www.test.co.uk/")
+	info := extractPageInfo(doc, pageURL)
+	markExternal(&info, pageURL)
+	if len(info.External) != 1 || info.External["https://other.co.uk/"] != "other.co.uk" {
+		t.Errorf("Expected only the other.co.uk link to be external, got %v", info.External)
+	}
+}
+
+func TestExtractPageInfoRelativeLinks(t *testing.T) {
+	htmlStr := `<html><body><a href="?page=2">Next</a><a href="//cdn.test/x">CDN</a><a href="../up">Up</a><a href="mailto:a@test.com">Mail</a></body></html>`
+	doc, err := htmlparser.Parse(strings.NewReader(htmlStr))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	pageURL, _ := neturl.Parse("https://user@test.com:8443/a/b?page=1")
+	info := extractPageInfo(doc, pageURL)
+	want := []string{"https://user@test.com:8443/a/b?page=2", "https://cdn.test/x", "https://user@test.com:8443/up", "mailto:a@test.com"}
+	if len(info.Links) != len(want) {
+		t.Fatalf("Expected %d links, got %v", len(want), info.Links)
+	}
+	for i, link := range info.Links {
+		if link.Href != want[i] {
+			t.Errorf("Link %d: expected %s, got %s", i, want[i], link.Href)
+		}
+	}
+}
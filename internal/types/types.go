@@ -121,6 +121,159 @@ func (ua UserAgent) String() string {
 	return string(ua)
 }
 
+// Credentials contient les informations d'authentification HTTP
+type Credentials struct {
+	Username    string // authentification basique si non vide
+	Password    string
+	BearerToken string // prioritaire sur l'authentification basique
+	Host        string // hôte (avec port éventuel) auquel les envoyer, tous si vide
+}
+
+// IsEmpty retourne true si aucune authentification n'est configurée
+func (c Credentials) IsEmpty() bool {
+	return c.Username == "" && c.BearerToken == ""
+}
+
+// String retourne une description sans secret, utilisable dans les logs
+func (c Credentials) String() string {
+	switch {
+	case c.BearerToken != "":
+		return "bearer token"
+	case c.Username != "":
+		return fmt.Sprintf("basic auth (%s)", c.Username)
+	default:
+		return "none"
+	}
+}
+
+// LoginForm décrit une connexion scriptée par formulaire avant l'extraction
+type LoginForm struct {
+	URL           string            // page contenant le formulaire de connexion
+	Fields        map[string]string // valeurs des champs nommés à remplir
+	CheckSelector string            // sélecteur qui doit être présent après la connexion
+}
+
+// IsEmpty retourne true si aucune connexion n'est configurée
+func (lf LoginForm) IsEmpty() bool {
+	return lf.URL == ""
+}
+
+// TLSConfig décrit la configuration TLS du client HTTP
+type TLSConfig struct {
+	CAFile             string // bundle PEM de CA ajouté aux autorités du système
+	CertFile           string // certificat client PEM (TLS mutuel)
+	KeyFile            string // clé privée PEM du certificat client
+	MinVersion         string // version minimale : "1.0", "1.1", "1.2" ou "1.3"
+	InsecureSkipVerify bool   // désactive la vérification des certificats (dangereux)
+}
+
+// IsEmpty retourne true si la configuration TLS par défaut est utilisée
+func (tc TLSConfig) IsEmpty() bool {
+	return tc == TLSConfig{}
+}
+
+// SafeMode restreint les destinations accessibles quand les URLs viennent d'utilisateurs
+// non fiables : adresses internes bloquées, ports et schémas limités.
+type SafeMode struct {
+	Enabled bool
+	Ports   []int    // ports autorisés, tous si vide
+	Schemes []string // schémas autorisés, http et https si vide
+	Allow   []string // hôtes ("intranet.example.com", "*.example.com") ou plages CIDR toujours autorisés
+}
+
+// FetchMetadata décrit la récupération d'un document
+type FetchMetadata struct {
+	URL         string   // URL demandée
+	FinalURL    string   // URL après redirections
+	StatusCode  int      // 0 pour un document local
+	ContentType string   // Content-Type déclaré ou déduit de l'extension
+	TLS         *TLSInfo // nil hors HTTPS
+	Canonical   string   // URL absolue du <link rel="canonical">, vide si absent
+	BodySize    int64    // octets du corps lu (décompressé)
+}
+
+// TLSInfo résume la connexion TLS négociée
+type TLSInfo struct {
+	Version          string // "TLS 1.3"
+	CipherSuite      string
+	ServerName       string
+	PeerCertificates []CertificateSummary // certificat du serveur en premier
+}
+
+// CertificateSummary résume un certificat présenté par le serveur
+type CertificateSummary struct {
+	Subject   string
+	Issuer    string
+	DNSNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	SHA256    string // empreinte hexadécimale du certificat DER
+}
+
+// String retourne une représentation string
+func (ti *TLSInfo) String() string {
+	if ti == nil {
+		return "none"
+	}
+	if len(ti.PeerCertificates) == 0 {
+		return ti.Version
+	}
+	leaf := ti.PeerCertificates[0]
+	return fmt.Sprintf("%s, %s issued by %s, expires %s", ti.Version, leaf.Subject, leaf.Issuer, leaf.NotAfter.Format("2006-01-02"))
+}
+
+// CrawlScope détermine quels liens découverts le crawl peut suivre
+type CrawlScope string
+
+const (
+	ScopeHost   CrawlScope = "host"   // même hôte que la page de départ
+	ScopeDomain CrawlScope = "domain" // même domaine enregistrable, sous-domaines compris
+	ScopePrefix CrawlScope = "prefix" // même hôte et chemin sous le répertoire de départ
+)
+
+// ParseCrawlScope valide une portée de crawl
+func ParseCrawlScope(s string) (CrawlScope, error) {
+	switch scope := CrawlScope(strings.ToLower(s)); scope {
+	case ScopeHost, ScopeDomain, ScopePrefix:
+		return scope, nil
+	}
+	return "", fmt.Errorf("invalid crawl scope %q (expected host, domain or prefix)", s)
+}
+
+// CrawlConfig décrit un parcours en largeur à partir de l'URL de départ
+type CrawlConfig struct {
+	Enabled  bool
+	MaxDepth int        // profondeur maximale, 0 = page de départ seulement
+	MaxPages int        // nombre maximal de pages, DefaultCrawlPages si 0
+	Scope    CrawlScope // ScopeHost si vide
+	Workers  int        // pages traitées en parallèle, DefaultCrawlWorkers si 0
+	StateDir string     // répertoire des points de reprise, vide = pas de reprise possible
+	Resume   bool       // reprend le crawl enregistré dans StateDir
+	Graph    string     // fichier du graphe des liens (.dot, .gv, .graphml ou .csv), vide = pas d'export
+	Report   string     // fichier du rapport de liens cassés, vide = pas de vérification
+}
+
+// BatchConfig décrit l'extraction d'une liste d'URLs avec les mêmes sélecteurs
+type BatchConfig struct {
+	Source   string           // fichier d'URLs, une par ligne ; "-" pour l'entrée standard
+	Template *neturl.Template // modèle d'URL développé à la place de Source
+	Workers  int              // URLs traitées en parallèle, DefaultBatchWorkers si 0
+}
+
+// Enabled retourne true si un lot d'URLs est à traiter
+func (bc BatchConfig) Enabled() bool {
+	return bc.Source != "" || bc.Template != nil
+}
+
+// DefaultBatchWorkers est le nombre d'URLs d'un lot traitées en parallèle par défaut
+const DefaultBatchWorkers = 4
+
+const (
+	DefaultCrawlDepth   = 2
+	DefaultCrawlPages   = 100
+	DefaultCrawlWorkers = 4
+)
+
 // ExtractionMode représente le mode d'extraction
 type ExtractionMode int
 
@@ -132,11 +285,44 @@ const (
 // ExtractionConfig contient la configuration pour une extraction
 type ExtractionConfig struct {
 	URL            string
+	BaseURL        string // URL de base pour résoudre les liens, URL si vide
 	Selectors      SelectorList
 	OutputPath     OutputPath
 	Timeout        time.Duration
+	Deadline       time.Duration // durée maximale de l'exécution complète, 0 = illimitée
 	Mode           ExtractionMode
 	StructuredData map[string]any
+	IgnoreRobots   bool
+	RateLimit      float64 // requêtes par seconde et par hôte, 0 = illimité
+	Burst          int
+	MaxConns       int // connexions simultanées par hôte, 0 = illimité
+	Credentials    Credentials
+	Login          LoginForm
+	Request        RequestConfig // requête initiale (méthode, corps, formulaire)
+	TLS            TLSConfig
+	Safe           SafeMode
+	HARRecord      string // fichier HAR où enregistrer les échanges
+	HARReplay      string // fichier HAR dont les réponses sont rejouées hors ligne
+	WARCRecord     string // archive WARC où écrire les échanges (.gz : gzip par enregistrement)
+	WARCInput      string // archive WARC dont les pages HTML sont extraites hors ligne
+	Crawl          CrawlConfig
+	Batch          BatchConfig
+	Normalize      neturl.Rules  // règles de normalisation des URLs pour la déduplication
+	Include        []string      // motifs des liens suivis (glob, ou "re:" et une expression régulière)
+	Exclude        []string      // motifs des liens jamais suivis, prioritaires sur Include
+	NextSelector   string        // sélecteur du lien vers la page suivante
+	MaxPages       int           // nombre maximal de pages parcourues, 1 = pas de pagination
+	MetaRefresh    int           // nombre maximal de meta refresh suivis, 0 = désactivé
+	UseCanonical   bool          // identifie les pages par leur URL canonique dans les sorties
+	UnicodeHosts   bool          // écrit les hôtes internationalisés en Unicode plutôt qu'en punycode
+	MaxBodySize    int64         // taille maximale d'une réponse en octets, 0 = illimitée
+	ByteBudget     int64         // octets téléchargés au total avant l'arrêt, 0 = illimité
+	TimeBudget     time.Duration // durée des téléchargements avant l'arrêt avec résultats partiels, 0 = illimitée
+}
+
+// Paginates retourne true si l'extraction suit les pages suivantes
+func (ec *ExtractionConfig) Paginates() bool {
+	return ec.NextSelector != "" || ec.MaxPages > 1
 }
 
 // NewExtractionConfig crée une nouvelle configuration d'extraction
@@ -147,6 +333,7 @@ func NewExtractionConfig(url string, selectors string, outputPath string, timeou
 		OutputPath: NewOutputPath(outputPath),
 		Timeout:    timeout,
 		Mode:       ModeSelectorBased,
+		Normalize:  neturl.DefaultRules,
 	}
 }
 
@@ -233,9 +420,12 @@ func (er *ExtractionResult) String() string {
 
 // FetchRequest représente une requête de récupération
 type FetchRequest struct {
-	URL       string
-	UserAgent UserAgent
-	Timeout   time.Duration
+	URL         string
+	UserAgent   UserAgent
+	Timeout     time.Duration
+	Method      string // GET si vide
+	Body        []byte
+	ContentType string // Content-Type du corps
 }
 
 // NewFetchRequest crée une nouvelle requête de récupération
@@ -244,9 +434,25 @@ func NewFetchRequest(url string, timeout time.Duration) *FetchRequest {
 		URL:       url,
 		UserAgent: DefaultUserAgent,
 		Timeout:   timeout,
+		Method:    "GET",
 	}
 }
 
+// RequestConfig décrit la requête initiale quand ce n'est pas un simple GET
+type RequestConfig struct {
+	Method     string            // méthode HTTP, GET par défaut (POST si un corps est fourni)
+	Fields     neturl.Values     // champs envoyés, ou valeurs imposées au formulaire soumis
+	Files      map[string]string // champ → chemin du fichier envoyé en multipart
+	Multipart  bool              // encode les champs en multipart/form-data
+	JSON       string            // corps JSON brut
+	SubmitForm string            // sélecteur du <form> à soumettre depuis la page -url
+}
+
+// IsEmpty retourne true si la requête initiale est un simple GET
+func (rc RequestConfig) IsEmpty() bool {
+	return rc.Method == "" && len(rc.Fields) == 0 && len(rc.Files) == 0 && rc.JSON == "" && rc.SubmitForm == ""
+}
+
 // FetchResult représente le résultat d'une récupération
 type FetchResult struct {
 	Document *htmlparser.Node
@@ -2,26 +2,49 @@ package types
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+
+	"webextractor/internal/neturl"
 )
 
 // URLString représente une URL sous forme de chaîne validée
 type URLString string
 
-// NewURLString crée une nouvelle URL string après validation basique
+// StdinURL désigne un document lu depuis l'entrée standard
+const StdinURL URLString = "-"
+
+// NewURLString crée une nouvelle URL string après validation basique.
+// Sont acceptés : les URLs http(s), les URLs file://, "-" pour l'entrée standard
+// et les chemins de fichiers existants (convertis en URL file:// absolue).
 func NewURLString(url string) (URLString, error) {
 	url = strings.TrimSpace(url)
 	if url == "" {
 		return "", fmt.Errorf("URL cannot be empty")
 	}
 
+	if url == string(StdinURL) {
+		return StdinURL, nil
+	}
+
 	// Validation basique du format
-	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-		return "", fmt.Errorf("URL must start with http:// or https://")
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "file://") {
+		return URLString(url), nil
+	}
+
+	// Sinon on essaie de l'interpréter comme un chemin local
+	if !strings.Contains(url, "://") {
+		if info, err := os.Stat(url); err == nil && !info.IsDir() {
+			abs, err := filepath.Abs(url)
+			if err != nil {
+				return "", fmt.Errorf("invalid file path: %w", err)
+			}
+			return URLString("file://" + neturl.EscapePath(filepath.ToSlash(abs))), nil
+		}
 	}
 
-	return URLString(url), nil
+	return "", fmt.Errorf("URL must start with http://, https:// or file://, or be an existing file")
 }
 
 // String retourne la représentation string
@@ -31,10 +54,14 @@ func (u URLString) String() string {
 
 // Scheme retourne le schéma de l'URL
 func (u URLString) Scheme() string {
-	if strings.HasPrefix(string(u), "https://") {
+	switch {
+	case strings.HasPrefix(string(u), "https://"):
 		return "https"
+	case u.IsLocal():
+		return "file"
+	default:
+		return "http"
 	}
-	return "http"
 }
 
 // IsSecure retourne true si l'URL utilise HTTPS
@@ -42,6 +69,16 @@ func (u URLString) IsSecure() bool {
 	return u.Scheme() == "https"
 }
 
+// IsStdin retourne true si le document est lu depuis l'entrée standard
+func (u URLString) IsStdin() bool {
+	return u == StdinURL
+}
+
+// IsLocal retourne true si le document est lu sans accès réseau (fichier ou entrée standard)
+func (u URLString) IsLocal() bool {
+	return u.IsStdin() || strings.HasPrefix(string(u), "file://")
+}
+
 // CSSSelector représente un sélecteur CSS validé
 type CSSSelector string
 
@@ -0,0 +1,119 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"webextractor/internal/strconv"
+)
+
+// ErrInvalidRecord est retournée quand l'archive n'est pas au format WARC.
+var ErrInvalidRecord = errors.New("invalid WARC record")
+
+// maxRecordSize borne la taille d'un bloc lu en mémoire.
+const maxRecordSize = 1 << 30
+
+// Reader lit les enregistrements d'une archive WARC, compressée ou non.
+type Reader struct {
+	br *bufio.Reader
+}
+
+// NewReader retourne un Reader. La compression gzip (un membre par enregistrement
+// ou un seul pour tout le fichier) est détectée automatiquement.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		// gzip.Reader enchaîne les membres successifs par défaut
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("warc: %w", err)
+		}
+		br = bufio.NewReader(zr)
+	}
+	return &Reader{br: br}, nil
+}
+
+// Next retourne l'enregistrement suivant, ou io.EOF à la fin de l'archive.
+func (r *Reader) Next() (*Record, error) {
+	// Les lignes vides séparant les enregistrements sont ignorées
+	var line string
+	for {
+		l, err := r.br.ReadString('\n')
+		if err != nil && (err != io.EOF || l == "") {
+			return nil, err
+		}
+		line = strings.TrimRight(l, "\r\n")
+		if line != "" {
+			break
+		}
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+	if !strings.HasPrefix(line, "WARC/") {
+		return nil, fmt.Errorf("%w: unexpected line %q", ErrInvalidRecord, line)
+	}
+
+	record := &Record{Version: line}
+	for {
+		l, err := r.br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("%w: truncated header: %v", ErrInvalidRecord, err)
+		}
+		l = strings.TrimRight(l, "\r\n")
+		if l == "" {
+			break
+		}
+		name, value, ok := strings.Cut(l, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed field %q", ErrInvalidRecord, l)
+		}
+		record.Fields = append(record.Fields, Field{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+
+	length, err := strconv.Atoi(record.Get("Content-Length"))
+	if err != nil || length < 0 || length > maxRecordSize {
+		return nil, fmt.Errorf("%w: invalid Content-Length %q", ErrInvalidRecord, record.Get("Content-Length"))
+	}
+	// Le bloc grandit au fil de la lecture : une longueur annoncée mensongère
+	// n'entraîne pas d'allocation démesurée
+	if record.Block, err = io.ReadAll(io.LimitReader(r.br, int64(length))); err != nil {
+		return nil, fmt.Errorf("%w: truncated block: %v", ErrInvalidRecord, err)
+	}
+	if len(record.Block) < length {
+		return nil, fmt.Errorf("%w: truncated block: %d of %d bytes", ErrInvalidRecord, len(record.Block), length)
+	}
+
+	// Chaque enregistrement se termine par deux CRLF
+	trailer, err := r.br.Peek(4)
+	if err == nil && bytes.Equal(trailer, []byte("\r\n\r\n")) {
+		_, _ = r.br.Discard(4)
+	}
+	return record, nil
+}
+
+// Each appelle fn pour chaque enregistrement jusqu'à la fin de l'archive
+// ou jusqu'à la première erreur, de lecture ou retournée par fn.
+func (r *Reader) Each(fn func(*Record) error) error {
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
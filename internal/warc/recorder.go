@@ -0,0 +1,213 @@
+package warc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted remplace la valeur des en-têtes contenant des secrets.
+const redacted = "REDACTED"
+
+// doer est satisfaite par *http.Client et par les Doer du fetcher.
+type doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Recorder écrit un enregistrement request et un enregistrement response pour chaque
+// échange effectué par le Doer qu'il enveloppe. Le fichier est créé à la première
+// requête (ou à la fermeture) et commence par un enregistrement warcinfo ; un nom se
+// terminant par ".gz" active la compression gzip par enregistrement.
+type Recorder struct {
+	next     doer
+	path     string
+	software string
+
+	once   sync.Once
+	file   *os.File
+	writer *Writer
+	err    error
+}
+
+// NewRecorder retourne un Recorder qui transmet les requêtes à next et archive
+// les échanges dans path. software identifie l'outil dans l'enregistrement warcinfo.
+func NewRecorder(next doer, path, software string) *Recorder {
+	return &Recorder{next: next, path: path, software: software}
+}
+
+// Do exécute la requête puis archive la requête et la réponse complètes, précédées de
+// chaque redirection suivie (sans son corps, que le client a déjà écarté).
+// Le corps de la réponse est lu entièrement puis rendu à l'appelant.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	reqBody, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Now()
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if resp.Request == nil {
+		resp.Request = req
+	}
+	// Le client suit les redirections : chaque étape est archivée, dans l'ordre
+	for _, hop := range redirects(resp) {
+		if err := r.writeExchange(date, hop.Request, reqBody, hop, nil); err != nil {
+			return nil, err
+		}
+		// Seules les redirections 307 et 308 renvoient le corps de la requête
+		if hop.StatusCode != http.StatusTemporaryRedirect && hop.StatusCode != http.StatusPermanentRedirect {
+			reqBody = nil
+		}
+	}
+	if err := r.writeExchange(date, resp.Request, reqBody, resp, body); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// writeExchange archive une requête et sa réponse.
+func (r *Recorder) writeExchange(date time.Time, req *http.Request, reqBody []byte, resp *http.Response, body []byte) error {
+	target := req.URL.String()
+
+	response := NewRecord(TypeResponse, date, ContentTypeResponse, httpResponseBlock(resp, body))
+	response.Set("WARC-Target-URI", target)
+	response.Set("WARC-Payload-Digest", Digest(body))
+
+	request := NewRecord(TypeRequest, date, ContentTypeRequest, httpRequestBlock(req, reqBody))
+	request.Set("WARC-Target-URI", target)
+	request.Set("WARC-Concurrent-To", response.Get("WARC-Record-ID"))
+
+	for _, record := range []*Record{request, response} {
+		if err := r.writer.WriteRecord(record); err != nil {
+			return fmt.Errorf("warc: write %s record: %w", record.Type(), err)
+		}
+	}
+	return nil
+}
+
+// redirects retourne les réponses de redirection qui ont mené à resp, dans l'ordre.
+func redirects(resp *http.Response) []*http.Response {
+	var hops []*http.Response
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		hops = append([]*http.Response{req.Response}, hops...)
+	}
+	return hops
+}
+
+// Close crée l'archive si aucun échange n'a eu lieu puis ferme le fichier.
+func (r *Recorder) Close() error {
+	if err := r.open(); err != nil {
+		return err
+	}
+	return r.file.Close()
+}
+
+// open crée le fichier et écrit l'enregistrement warcinfo, une seule fois.
+func (r *Recorder) open() error {
+	r.once.Do(func() {
+		file, err := os.Create(r.path) // #nosec G304 - fichier explicitement choisi par l'utilisateur
+		if err != nil {
+			r.err = fmt.Errorf("warc: %w", err)
+			return
+		}
+		r.file = file
+		r.writer = NewWriter(file, strings.HasSuffix(r.path, ".gz"))
+
+		info := "software: " + r.software + "\r\nformat: WARC File Format 1.1\r\n"
+		record := NewRecord(TypeWarcinfo, time.Now(), "application/warc-fields", []byte(info))
+		if err := r.writer.WriteRecord(record); err != nil {
+			r.err = fmt.Errorf("warc: %w", err)
+		}
+	})
+	return r.err
+}
+
+// requestBody lit le corps de la requête sans le consommer.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// httpRequestBlock reconstruit le message HTTP de la requête.
+func httpRequestBlock(req *http.Request, body []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", req.Method, req.URL.RequestURI())
+	fmt.Fprintf(&buf, "Host: %s\r\n", req.URL.Host)
+	writeHeaders(&buf, req.Header)
+	if len(body) > 0 {
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n", len(body))
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// httpResponseBlock reconstruit le message HTTP de la réponse. Le corps ayant été
+// décodé par le client, Content-Length est recalculé et Transfer-Encoding retiré.
+func httpResponseBlock(resp *http.Response, body []byte) []byte {
+	header := resp.Header.Clone()
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", fmt.Sprint(len(body)))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HTTP/1.1 %s\r\n", resp.Status)
+	writeHeaders(&buf, header)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// writeHeaders écrit les en-têtes triés, sans les secrets.
+func writeHeaders(buf *bytes.Buffer, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			switch http.CanonicalHeaderKey(name) {
+			case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie":
+				value = redacted
+			}
+			fmt.Fprintf(buf, "%s: %s\r\n", name, value)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package warc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteAndReadRecords(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, compress)
+
+		info := NewRecord(TypeWarcinfo, testDate(), "application/warc-fields", []byte("software: test\r\n"))
+		resp := NewRecord(TypeResponse, testDate(), ContentTypeResponse,
+			[]byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nContent-Length: 13\r\n\r\n<p>hello</p>\n"))
+		resp.Set("WARC-Target-URI", "https://example.com/")
+		for _, r := range []*Record{info, resp} {
+			if err := w.WriteRecord(r); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+		}
+		if compress && !bytes.HasPrefix(buf.Bytes(), []byte{0x1f, 0x8b}) {
+			t.Fatalf("expected gzip output")
+		}
+
+		reader, err := NewReader(&buf)
+		if err != nil {
+			t.Fatalf("reader: %v", err)
+		}
+		var records []*Record
+		if err := reader.Each(func(r *Record) error {
+			records = append(records, r)
+			return nil
+		}); err != nil {
+			t.Fatalf("read (gzip=%v): %v", compress, err)
+		}
+
+		if len(records) != 2 || records[0].Type() != TypeWarcinfo || records[0].Version != Version {
+			t.Fatalf("unexpected records (gzip=%v): %+v", compress, records)
+		}
+		if records[1].TargetURI() != "https://example.com/" || records[1].Get("WARC-Block-Digest") != Digest(records[1].Block) {
+			t.Fatalf("unexpected response record: %+v", records[1].Fields)
+		}
+		httpResp, err := records[1].HTTPResponse()
+		if err != nil {
+			t.Fatalf("http response: %v", err)
+		}
+		body, _ := io.ReadAll(httpResp.Body)
+		if httpResp.StatusCode != 200 || string(body) != "<p>hello</p>\n" {
+			t.Fatalf("unexpected HTTP response: %d %q", httpResp.StatusCode, body)
+		}
+	}
+}
+
+func TestReaderRejectsGarbage(t *testing.T) {
+	reader, err := NewReader(strings.NewReader("<html></html>"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := reader.Next(); err == nil {
+		t.Fatalf("expected error for non-WARC input")
+	}
+
+	// Longueur annoncée bien plus grande que le bloc présent
+	reader, _ = NewReader(strings.NewReader("WARC/1.1\r\nWARC-Type: resource\r\nContent-Length: 1000000000\r\n\r\nshort"))
+	if _, err := reader.Next(); !errors.Is(err, ErrInvalidRecord) {
+		t.Fatalf("expected truncated block error, got %v", err)
+	}
+}
+
+func TestRecorderRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<h1>Moved</h1>"))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "run.warc")
+	rec := NewRecorder(http.DefaultClient, path, "test/1")
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/old", nil)
+	if _, err := rec.Do(req); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	rec.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer file.Close()
+	reader, _ := NewReader(file)
+	var exchanges []string
+	_ = reader.Each(func(r *Record) error {
+		if r.Type() == TypeResponse {
+			resp, err := r.HTTPResponse()
+			if err != nil {
+				t.Fatalf("response: %v", err)
+			}
+			exchanges = append(exchanges, fmt.Sprintf("%s %d", strings.TrimPrefix(r.TargetURI(), srv.URL), resp.StatusCode))
+		}
+		return nil
+	})
+	if strings.Join(exchanges, ",") != "/old 301,/new 200" {
+		t.Fatalf("expected each redirect to be archived, got %v", exchanges)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte("<h1>Archived</h1>"))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "run.warc.gz")
+	rec := NewRecorder(http.DefaultClient, path, "test/1")
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+"/form", strings.NewReader("q=go"))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := rec.Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "<h1>Archived</h1>" {
+		t.Fatalf("body not returned to the caller: %q", body)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer file.Close()
+	reader, err := NewReader(file)
+	if err != nil {
+		t.Fatalf("reader: %v", err)
+	}
+
+	var types []string
+	var request, response *Record
+	_ = reader.Each(func(r *Record) error {
+		types = append(types, r.Type())
+		switch r.Type() {
+		case TypeRequest:
+			request = r
+		case TypeResponse:
+			response = r
+		}
+		return nil
+	})
+	if strings.Join(types, ",") != "warcinfo,request,response" {
+		t.Fatalf("unexpected record types: %v", types)
+	}
+	if request.Get("WARC-Concurrent-To") != response.Get("WARC-Record-ID") {
+		t.Errorf("request not linked to its response")
+	}
+	if !strings.HasPrefix(string(request.Block), "POST /form HTTP/1.1\r\n") || !strings.HasSuffix(string(request.Block), "\r\n\r\nq=go") {
+		t.Errorf("unexpected request block: %q", request.Block)
+	}
+	for _, block := range [][]byte{request.Block, response.Block} {
+		if bytes.Contains(block, []byte("secret")) {
+			t.Errorf("secret header not redacted: %q", block)
+		}
+	}
+}
+
+func testDate() time.Time {
+	return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,55 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Writer écrit des enregistrements WARC. En mode gzip, chaque enregistrement est un
+// membre gzip distinct, ce qui permet d'accéder à un enregistrement sans tout décompresser.
+// Il est sûr pour un usage concurrent.
+type Writer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	gzip bool
+}
+
+// NewWriter retourne un Writer ; compress active la compression gzip par enregistrement.
+func NewWriter(w io.Writer, compress bool) *Writer {
+	return &Writer{w: w, gzip: compress}
+}
+
+// WriteRecord écrit un enregistrement complet. Content-Length est calculé à partir du bloc.
+func (w *Writer) WriteRecord(r *Record) error {
+	var buf bytes.Buffer
+	version := r.Version
+	if version == "" {
+		version = Version
+	}
+	buf.WriteString(version + "\r\n")
+	for _, f := range r.Fields {
+		if f.Name == "Content-Length" {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s: %s\r\n", f.Name, f.Value)
+	}
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n", len(r.Block))
+	buf.Write(r.Block)
+	buf.WriteString("\r\n\r\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.gzip {
+		_, err := w.w.Write(buf.Bytes())
+		return err
+	}
+	zw := gzip.NewWriter(w.w)
+	if _, err := zw.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
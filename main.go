@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"webextractor/internal/app"
 	"webextractor/internal/cli"
@@ -18,9 +21,84 @@ func main() {
 	}
 
 	config := types.NewExtractionConfig(flags.URL.String(), flags.Sel, flags.Out.String(), flags.Timeout)
+	config.BaseURL = flags.BaseURL
+	config.Deadline = flags.Deadline
+	config.IgnoreRobots = flags.IgnoreRobots
+	config.RateLimit = flags.Rate
+	config.Burst = flags.Burst
+	config.MaxConns = flags.MaxConns
+	config.Credentials = types.Credentials{
+		Username:    flags.AuthUser,
+		Password:    flags.AuthPassword,
+		BearerToken: flags.AuthToken,
+		Host:        flags.AuthHost,
+	}
+	config.Request = types.RequestConfig{
+		Method:     flags.Method,
+		Fields:     flags.Fields,
+		Files:      flags.Files,
+		Multipart:  flags.Multipart,
+		JSON:       flags.JSON,
+		SubmitForm: flags.SubmitForm,
+	}
+	config.TLS = types.TLSConfig{
+		CAFile:             flags.CACert,
+		CertFile:           flags.ClientCert,
+		KeyFile:            flags.ClientKey,
+		MinVersion:         flags.TLSMinVersion,
+		InsecureSkipVerify: flags.InsecureSkipVerify,
+	}
+	config.Safe = types.SafeMode{
+		Enabled: flags.Safe,
+		Ports:   flags.AllowPorts,
+		Schemes: flags.AllowSchemes,
+		Allow:   flags.AllowHosts,
+	}
+	config.HARRecord = flags.HARRecord.String()
+	config.HARReplay = flags.HARReplay
+	config.WARCRecord = flags.WARCRecord.String()
+	config.WARCInput = flags.WARCInput
+	config.NextSelector = flags.Next
+	if flags.URLs != "" || flags.Template != nil {
+		config.Batch = types.BatchConfig{Source: flags.URLs, Template: flags.Template, Workers: flags.Workers}
+	}
+	config.Normalize = flags.Normalize
+	config.Include = flags.Include
+	config.Exclude = flags.Exclude
+	if flags.Crawl {
+		scope, _ := types.ParseCrawlScope(flags.Scope)
+		config.Crawl = types.CrawlConfig{
+			Enabled:  true,
+			MaxDepth: flags.Depth,
+			MaxPages: flags.MaxPages,
+			Scope:    scope,
+			Workers:  flags.Workers,
+			StateDir: flags.State,
+			Resume:   flags.Resume,
+			Graph:    flags.Graph,
+			Report:   flags.Report,
+		}
+	} else {
+		config.MaxPages = flags.MaxPages
+	}
+	config.MaxBodySize = flags.MaxBodySize
+	config.ByteBudget = flags.ByteBudget
+	config.TimeBudget = flags.TimeBudget
+	config.MetaRefresh = flags.FollowRefresh
+	config.UseCanonical = flags.Canonical
+	config.UnicodeHosts = flags.UnicodeHosts
+	config.Login = types.LoginForm{
+		URL:           flags.LoginURL,
+		Fields:        flags.LoginFields,
+		CheckSelector: flags.LoginCheck,
+	}
+
+	// SIGINT/SIGTERM annulent le contexte : l'exécution s'arrête proprement sans sortie partielle
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	application := app.New(config)
-	if err := application.Run(); err != nil {
+	if err := application.RunContext(ctx); err != nil {
 		log.Fatalf("❌ %v", err)
 	}
 }
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -157,3 +159,160 @@ func TestSelectorDeduplication(t *testing.T) {
 		t.Fatalf("Expected 4 unique selectors, got %d", len(finalSelectors))
 	}
 }
+
+func TestCLILocalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	if err := os.WriteFile(path, []byte(`<html><body><div>Offline</div></body></html>`), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	origStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	origArgs := os.Args
+	os.Args = []string{"cmd", "-url", path, "-sel", "div", "-base-url", "https://example.com/"}
+
+	main()
+
+	w.Close()
+	os.Stdout = origStdout
+	os.Args = origArgs
+
+	outBytes, _ := io.ReadAll(r)
+	out := string(outBytes)
+	if !strings.Contains(out, "Offline") {
+		t.Fatalf("output not correct: %s", out)
+	}
+}
+
+func TestCLIWARCRecordAndInput(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><div>Archived</div></body></html>`))
+	}))
+	archive := filepath.Join(t.TempDir(), "run.warc.gz")
+
+	runCLI := func(args ...string) string {
+		origStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		origArgs := os.Args
+		os.Args = append([]string{"cmd"}, args...)
+
+		main()
+
+		w.Close()
+		os.Stdout = origStdout
+		os.Args = origArgs
+
+		outBytes, _ := io.ReadAll(r)
+		return string(outBytes)
+	}
+
+	runCLI("-url", srv.URL+"/page", "-sel", "div", "-warc-record", archive)
+	srv.Close()
+
+	// Le serveur est arrêté : l'extraction se fait uniquement depuis l'archive
+	out := runCLI("-warc-input", archive, "-sel", "div")
+	if !strings.Contains(out, "Archived") || !strings.Contains(out, srv.URL+"/page") {
+		t.Fatalf("output not correct: %s", out)
+	}
+}
+
+func TestCLIPagination(t *testing.T) {
+	pages := map[string]string{
+		"/list":     `<html><body><p>un</p><a class="next" href="/list?p=2">›</a></body></html>`,
+		"/list?p=2": `<html><body><p>deux</p><a class="next" href="/list?p=3#top">›</a></body></html>`,
+		"/list?p=3": `<html><body><p>trois</p><a class="next" href="/list">›</a></body></html>`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	outPath := filepath.Join(t.TempDir(), "out.json")
+
+	origStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	origArgs := os.Args
+	os.Args = []string{"cmd", "-url", srv.URL + "/list", "-sel", "p", "-next", ".next", "-out", outPath}
+
+	main()
+
+	w.Close()
+	os.Stdout = origStdout
+	os.Args = origArgs
+
+	outBytes, _ := io.ReadAll(r)
+	if !strings.Contains(string(outBytes), "🔁") {
+		t.Errorf("expected loop detection, got: %s", outBytes)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var doc ioLib.DocumentResult
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if len(doc.Pages) != 3 || len(doc.Results) != 1 {
+		t.Fatalf("expected 3 pages and 1 selector, got %+v", doc)
+	}
+	got := doc.Results[0]
+	if strings.Join(got.Matches, ",") != "un,deux,trois" || got.URLs[2] != srv.URL+"/list?p=3" {
+		t.Fatalf("unexpected merged results: %+v", got)
+	}
+}
+
+func TestCLIURLTemplate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><h1>` + r.URL.Path + ` ` + r.URL.Query().Get("page") + `</h1></body></html>`))
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	terms := filepath.Join(dir, "terms.txt")
+	os.WriteFile(terms, []byte("a b\nc\n"), 0o644)
+	outPath := filepath.Join(dir, "out.ndjson")
+
+	origStdout := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+
+	origArgs := os.Args
+	os.Args = []string{"cmd", "-url", srv.URL + "/item/{term}?page={page:1..2}", "-param", "term=@" + terms, "-sel", "h1", "-out", outPath}
+
+	main()
+
+	w.Close()
+	os.Stdout = origStdout
+	os.Args = origArgs
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	matches := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var doc ioLib.DocumentResult
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		matches[doc.Params["term"]+"/"+doc.Params["page"]+"="+doc.Results[0].Matches[0]] = true
+	}
+	for _, want := range []string{"a b/1=/item/a b 1", "a b/2=/item/a b 2", "c/1=/item/c 1", "c/2=/item/c 2"} {
+		if !matches[want] {
+			t.Errorf("missing %q in %v", want, matches)
+		}
+	}
+}